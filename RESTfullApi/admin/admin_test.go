@@ -0,0 +1,56 @@
+package admin
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"math/rand"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestFakePeopleAreValid(t *testing.T) {
+
+	for _, person := range FakePeople(200, rand.New(rand.NewSource(1))) {
+		if err := person.Validate(); err != nil {
+			t.Fatalf("invalid fake person %+v: %v", person, err)
+		}
+	}
+}
+
+func TestDocumentsRoundTrip(t *testing.T) {
+
+	id := primitive.NewObjectID()
+
+	document, err := bson.Marshal(bson.D{{Key: "_id", Value: id}, {Key: "firstname", Value: "David"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var plain, compressed bytes.Buffer
+
+	gz := gzip.NewWriter(&compressed)
+	for _, w := range []io.Writer{&plain, gz} {
+		if err := writeDocument(w, document); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gz.Close()
+
+	for name, dump := range map[string]*bytes.Buffer{"plain": &plain, "gzip": &compressed} {
+		var documents []bson.Raw
+		err := readDocuments(dump, func(document bson.Raw) error {
+			documents = append(documents, document)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if len(documents) != 1 || documents[0].Lookup("_id").ObjectID() != id {
+			t.Fatalf("%s: unexpected documents %v", name, documents)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+package admin
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// maxLineSize bounds a line of a dump, mongo documents are at most 16MB and
+// their extended JSON is larger.
+const maxLineSize = 64 << 20
+
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// Dump writes every document of collection to w as canonical extended JSON,
+// one document per line, and returns the number of documents written. The
+// output is gzip compressed when compress is set.
+func Dump(ctx context.Context, collection *mongo.Collection, w io.Writer, compress bool) (int, error) {
+
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(w)
+		w = gz
+	}
+
+	buffered := bufio.NewWriter(w)
+
+	count := 0
+	for cursor.Next(ctx) {
+		if err := writeDocument(buffered, cursor.Current); err != nil {
+			return count, err
+		}
+		count++
+	}
+	if err := cursor.Err(); err != nil {
+		return count, err
+	}
+
+	if err := buffered.Flush(); err != nil {
+		return count, err
+	}
+
+	if gz != nil {
+		return count, gz.Close()
+	}
+
+	return count, nil
+}
+
+// Restore inserts the documents of a dump read from r in collection and
+// returns the number of documents inserted. Gzip compressed dumps are
+// detected and decompressed.
+func Restore(ctx context.Context, collection *mongo.Collection, r io.Reader) (int, error) {
+
+	inserted := 0
+	batch := make([]interface{}, 0, batchSize)
+
+	insert := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		result, err := collection.InsertMany(ctx, batch, options.InsertMany().SetOrdered(false))
+		if result != nil {
+			// the ids of the documents that failed are returned too
+			inserted += len(result.InsertedIDs)
+			var bulkErr mongo.BulkWriteException
+			if errors.As(err, &bulkErr) {
+				inserted -= len(bulkErr.WriteErrors)
+			}
+		}
+		batch = batch[:0]
+		return err
+	}
+
+	err := readDocuments(r, func(document bson.Raw) error {
+		batch = append(batch, document)
+		if len(batch) < batchSize {
+			return nil
+		}
+		return insert()
+	})
+	if err != nil {
+		return inserted, err
+	}
+
+	return inserted, insert()
+}
+
+func writeDocument(w io.Writer, document bson.Raw) error {
+
+	line, err := bson.MarshalExtJSON(document, true, false)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(line); err != nil {
+		return err
+	}
+
+	_, err = w.Write([]byte{'\n'})
+
+	return err
+}
+
+// readDocuments calls fn with every document of a dump, blank lines are
+// skipped.
+func readDocuments(r io.Reader, fn func(bson.Raw) error) error {
+
+	buffered := bufio.NewReader(r)
+
+	magic, err := buffered.Peek(len(gzipMagic))
+	if err != nil && err != io.EOF {
+		return err
+	}
+
+	var in io.Reader = buffered
+	if bytes.Equal(magic, gzipMagic) {
+		gz, err := gzip.NewReader(buffered)
+		if err != nil {
+			return err
+		}
+		defer gz.Close()
+		in = gz
+	}
+
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+
+	line := 0
+	for scanner.Scan() {
+		line++
+
+		content := bytes.TrimSpace(scanner.Bytes())
+		if len(content) == 0 {
+			continue
+		}
+
+		var document bson.Raw
+		if err := bson.UnmarshalExtJSON(content, true, &document); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if err := fn(document); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
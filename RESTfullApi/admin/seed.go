@@ -0,0 +1,69 @@
+// Package admin holds the maintenance tasks run as subcommands of main.
+package admin
+
+import (
+	"context"
+	"math/rand"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// batchSize is the number of documents sent per insert by Seed and Restore.
+const batchSize = 1000
+
+var (
+	firstnames = []string{
+		"Alice", "Bruno", "Carmen", "David", "Elena", "Farid", "Greta", "Hugo", "Ines", "Jonas",
+		"Kenji", "Laura", "Mateo", "Nadia", "Oscar", "Paula", "Quentin", "Rosa", "Samuel", "Tamara",
+		"Ulises", "Valeria", "Walter", "Ximena", "Yusuf", "Zoe",
+	}
+	lastnames = []string{
+		"Alvarez", "Becker", "Castillo", "Dubois", "Eriksen", "Fernandez", "Garcia", "Hernandez",
+		"Ibarra", "Jensen", "Kowalski", "Lopez", "Moreau", "Novak", "Olsen", "Petrov", "Quispe",
+		"Rossi", "Schmidt", "Tanaka", "Urrutia", "Vargas", "Weber", "Yilmaz", "Zapata",
+	}
+)
+
+// FakePeople returns count people with random names, all of them pass
+// data.Person.Validate.
+func FakePeople(count int, r *rand.Rand) data.People {
+
+	people := make(data.People, 0, count)
+	for i := 0; i < count; i++ {
+		people = append(people, &data.Person{
+			Firstname: firstnames[r.Intn(len(firstnames))],
+			Lastname:  lastnames[r.Intn(len(lastnames))],
+		})
+	}
+
+	return people
+}
+
+// Seed inserts count fake people in collection and returns how many were
+// inserted.
+func Seed(ctx context.Context, collection *mongo.Collection, count int, r *rand.Rand) (int, error) {
+
+	inserted := 0
+
+	for inserted < count {
+		people := FakePeople(min(batchSize, count-inserted), r)
+
+		documents := make([]interface{}, 0, len(people))
+		for _, person := range people {
+			documents = append(documents, person)
+		}
+
+		result, err := collection.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+		if result != nil {
+			inserted += len(result.InsertedIDs)
+		}
+		if err != nil {
+			return inserted, err
+		}
+	}
+
+	return inserted, nil
+}
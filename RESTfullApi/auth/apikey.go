@@ -0,0 +1,139 @@
+// Package auth authenticates the callers of the API.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// Scope is a permission granted to an API key.
+	Scope string
+
+	// APIKey is a stored key, the key itself is only known to its holder and
+	// is looked up by Hash.
+	APIKey struct {
+		ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+		Name       string             `json:"name" bson:"name"`
+		Prefix     string             `json:"prefix" bson:"prefix"`
+		Hash       string             `json:"-" bson:"hash"`
+		Scopes     []Scope            `json:"scopes" bson:"scopes"`
+		Tenant     string             `json:"tenant,omitempty" bson:"tenant,omitempty"`
+		CreatedAt  time.Time          `json:"createdAt" bson:"createdAt"`
+		ExpiresAt  *time.Time         `json:"expiresAt,omitempty" bson:"expiresAt,omitempty"`
+		LastUsedAt *time.Time         `json:"lastUsedAt,omitempty" bson:"lastUsedAt,omitempty"`
+		RevokedAt  *time.Time         `json:"revokedAt,omitempty" bson:"revokedAt,omitempty"`
+	}
+)
+
+const (
+	ScopeRead  Scope = "people:read"
+	ScopeWrite Scope = "people:write"
+	ScopeAdmin Scope = "admin"
+)
+
+const (
+	keyPrefix   = "pk_"
+	keyBytes    = 32
+	prefixChars = 8
+)
+
+var (
+	ErrInvalidKey = errors.New("invalid API key")
+	ErrExpiredKey = errors.New("expired API key")
+	ErrRevokedKey = errors.New("revoked API key")
+)
+
+// ParseScopes parses a comma separated list of scopes.
+func ParseScopes(value string) ([]Scope, error) {
+
+	var scopes []Scope
+	for _, s := range strings.Split(value, ",") {
+		if s = strings.TrimSpace(s); s != "" {
+			scopes = append(scopes, Scope(s))
+		}
+	}
+
+	return validScopes(scopes)
+}
+
+func validScopes(scopes []Scope) ([]Scope, error) {
+
+	if len(scopes) == 0 {
+		return nil, errors.New("at least one scope is required")
+	}
+
+	for _, scope := range scopes {
+		switch scope {
+		case ScopeRead, ScopeWrite, ScopeAdmin:
+		default:
+			return nil, fmt.Errorf("unknown scope %q", scope)
+		}
+	}
+
+	return scopes, nil
+}
+
+// NewAPIKey generates a key valid for ttl, zero meaning forever. The returned
+// secret is the key to hand to its holder, only its hash is kept.
+func NewAPIKey(name string, scopes []Scope, ttl time.Duration, now time.Time) (*APIKey, string, error) {
+
+	random := make([]byte, keyBytes)
+	if _, err := rand.Read(random); err != nil {
+		return nil, "", err
+	}
+
+	secret := keyPrefix + base64.RawURLEncoding.EncodeToString(random)
+
+	key := &APIKey{
+		Name:      name,
+		Prefix:    secret[:len(keyPrefix)+prefixChars],
+		Hash:      HashKey(secret),
+		Scopes:    scopes,
+		CreatedAt: now.UTC(),
+	}
+
+	if ttl > 0 {
+		expiresAt := key.CreatedAt.Add(ttl)
+		key.ExpiresAt = &expiresAt
+	}
+
+	return key, secret, nil
+}
+
+// HashKey returns the hash a key is stored under. Keys are random, so a fast
+// hash is enough to make a leaked collection useless.
+func HashKey(secret string) string {
+
+	sum := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Valid reports whether the key can be used at now.
+func (k *APIKey) Valid(now time.Time) error {
+
+	if k.RevokedAt != nil {
+		return ErrRevokedKey
+	}
+
+	if k.ExpiresAt != nil && !now.Before(*k.ExpiresAt) {
+		return ErrExpiredKey
+	}
+
+	return nil
+}
+
+// HasScope reports whether the key grants scope, admin grants every scope.
+func (k *APIKey) HasScope(scope Scope) bool {
+
+	return grants(k.Scopes, scope)
+}
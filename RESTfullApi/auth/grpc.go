@@ -0,0 +1,93 @@
+package auth
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// the gRPC metadata carrying the credentials, metadata keys are lower case
+const (
+	apiKeyMetadata        = "x-api-key"
+	authorizationMetadata = "authorization"
+)
+
+// UnaryServerInterceptor requires the scope scopes maps the method of a call
+// to, methods missing from scopes, such as health checks, are left open.
+func (a *Authenticator) UnaryServerInterceptor(scopes map[string]Scope) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		scope, ok := scopes[info.FullMethod]
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		ctx, err := a.authorizeCall(ctx, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func (a *Authenticator) StreamServerInterceptor(scopes map[string]Scope) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		scope, ok := scopes[info.FullMethod]
+		if !ok {
+			return handler(srv, ss)
+		}
+
+		ctx, err := a.authorizeCall(ss.Context(), scope)
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (a *Authenticator) authorizeCall(ctx context.Context, scope Scope) (context.Context, error) {
+
+	principal, err := a.authenticateRequest(ctx, firstValue(ctx, apiKeyMetadata), firstValue(ctx, authorizationMetadata))
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, status.FromContextError(ctxErr).Err()
+		}
+		if isKeyError(err) {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		a.logger.ErrorContext(ctx, errorAuthenticating, "error", err)
+		return nil, status.Error(codes.Unavailable, authenticationDown)
+	}
+
+	if !principal.HasScope(scope) {
+		return nil, status.Errorf(codes.PermissionDenied, insufficientScope, scope)
+	}
+
+	return NewContext(ctx, principal), nil
+}
+
+func firstValue(ctx context.Context, key string) string {
+
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// issueRequest is the body of POST /admin/keys, TTL is a Go duration such
+	// as 720h and an empty TTL issues a key that never expires.
+	issueRequest struct {
+		Name   string  `json:"name"`
+		Scopes []Scope `json:"scopes"`
+		TTL    string  `json:"ttl,omitempty"`
+		// Tenant binds the key to a tenant, empty keys can act on any.
+		Tenant string `json:"tenant,omitempty"`
+	}
+
+	// issueResponse is the only time the key itself is returned.
+	issueResponse struct {
+		*APIKey
+		Key string `json:"key"`
+	}
+)
+
+const (
+	errorDecodingKeyRequest = "Error while decoding the API key request"
+	errorIssuingKey         = "Error while issuing an API key"
+	errorListingKeys        = "Error while listing the API keys"
+	errorRevokingKey        = "Error while revoking an API key"
+	errorWrittingKeys       = "Error while writing the API keys response"
+	keyIssued               = "API key issued"
+	keyRevoked              = "API key revoked"
+	internalError           = "Internal Error"
+)
+
+// IssueKeyEndpoint creates a key and returns it along with its secret.
+func (a *Authenticator) IssueKeyEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	var req issueRequest
+	if err := json.NewDecoder(io.LimitReader(request.Body, 1<<16)).Decode(&req); err != nil {
+		a.logger.InfoContext(request.Context(), errorDecodingKeyRequest, "error", err)
+		a.writeError(response, request, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if req.Name == "" {
+		a.writeError(response, request, http.StatusBadRequest, "name is required")
+		return
+	}
+
+	scopes, err := validScopes(req.Scopes)
+	if err != nil {
+		a.writeError(response, request, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var ttl time.Duration
+	if req.TTL != "" {
+		if ttl, err = time.ParseDuration(req.TTL); err != nil || ttl <= 0 {
+			a.writeError(response, request, http.StatusBadRequest, "ttl must be a positive duration such as 720h")
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), a.timeout)
+	defer cancel()
+
+	key, secret, err := a.Issue(ctx, req.Name, scopes, ttl, req.Tenant)
+	if err != nil {
+		a.logger.ErrorContext(request.Context(), errorIssuingKey, "error", err)
+		a.writeError(response, request, http.StatusInternalServerError, internalError)
+		return
+	}
+
+	response.Header().Set(setContentType, jsonType)
+	response.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(response).Encode(issueResponse{APIKey: key, Key: secret}); err != nil {
+		a.logger.ErrorContext(request.Context(), errorWrittingKeys, "error", err)
+	}
+}
+
+// ListKeysEndpoint returns every key, without their secrets.
+func (a *Authenticator) ListKeysEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	ctx, cancel := context.WithTimeout(request.Context(), a.timeout)
+	defer cancel()
+
+	keys, err := a.store.List(ctx)
+	if err != nil {
+		a.logger.ErrorContext(request.Context(), errorListingKeys, "error", err)
+		a.writeError(response, request, http.StatusInternalServerError, internalError)
+		return
+	}
+
+	response.Header().Set(setContentType, jsonType)
+
+	if err := json.NewEncoder(response).Encode(keys); err != nil {
+		a.logger.ErrorContext(request.Context(), errorWrittingKeys, "error", err)
+	}
+}
+
+// RevokeKeyEndpoint revokes the key with the id of the path and answers 204,
+// keys are kept revoked rather than deleted so that their use stays
+// auditable.
+func (a *Authenticator) RevokeKeyEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	paramsId := mux.Vars(request)["id"]
+
+	id, err := primitive.ObjectIDFromHex(paramsId)
+	if err != nil {
+		a.writeError(response, request, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(request.Context(), a.timeout)
+	defer cancel()
+
+	revoked, err := a.store.Revoke(ctx, id, a.now())
+	if err != nil {
+		a.logger.ErrorContext(request.Context(), errorRevokingKey, "id", paramsId, "error", err)
+		a.writeError(response, request, http.StatusInternalServerError, internalError)
+		return
+	}
+
+	if revoked == 0 {
+		a.writeError(response, request, http.StatusNotFound, "No active API key was found with the id: "+paramsId)
+		return
+	}
+
+	a.logger.InfoContext(request.Context(), keyRevoked, "id", paramsId)
+	response.WriteHeader(http.StatusNoContent)
+}
+
+// Issue creates a key valid for ttl, zero meaning forever, and bound to
+// tenant when it is set, and returns it along with its secret.
+func (a *Authenticator) Issue(ctx context.Context, name string, scopes []Scope, ttl time.Duration, tenant string) (*APIKey, string, error) {
+
+	key, secret, err := NewAPIKey(name, scopes, ttl, a.now())
+	if err != nil {
+		return nil, "", err
+	}
+	key.Tenant = tenant
+
+	if key.ID, err = a.store.Create(ctx, key); err != nil {
+		return nil, "", err
+	}
+
+	a.logger.InfoContext(ctx, keyIssued, "name", key.Name, "prefix", key.Prefix, "scopes", key.Scopes)
+
+	return key, secret, nil
+}
+
+func writeMessage(w io.Writer, message string) error {
+
+	return json.NewEncoder(w).Encode(struct {
+		Message string `json:"message"`
+	}{message})
+}
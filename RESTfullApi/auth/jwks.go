@@ -0,0 +1,258 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// KeySet is a JSON Web Key Set read from a file or an http(s) URL. Keys
+	// are cached for a TTL and refetched early when a token names an unknown
+	// key, at most once per refresh interval.
+	KeySet struct {
+		source          string
+		client          *http.Client
+		ttl             time.Duration
+		refreshInterval time.Duration
+		now             func() time.Time
+
+		mu          sync.Mutex
+		keys        map[string]jwk
+		fetchedAt   time.Time
+		attemptedAt time.Time
+	}
+
+	jwk struct {
+		alg string
+		key interface{}
+	}
+
+	jsonWebKey struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Alg string `json:"alg"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+		Crv string `json:"crv"`
+		X   string `json:"x"`
+		Y   string `json:"y"`
+		K   string `json:"k"`
+	}
+
+	keySetOption func(keySet *KeySet)
+)
+
+const maxKeySetSize = 1 << 20
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// NewKeySet returns the key set of source, a file path or an http(s) URL.
+// Keys are fetched on first use.
+func NewKeySet(source string, opts ...keySetOption) *KeySet {
+	keySet := &KeySet{
+		source:          source,
+		client:          &http.Client{Timeout: 10 * time.Second},
+		ttl:             15 * time.Minute,
+		refreshInterval: time.Minute,
+		now:             time.Now,
+	}
+
+	for i := range opts {
+		opts[i](keySet)
+	}
+
+	return keySet
+}
+
+// WithKeySetTTL sets how long fetched keys are used before being refetched.
+func WithKeySetTTL(ttl time.Duration) keySetOption {
+	return func(keySet *KeySet) {
+		keySet.ttl = ttl
+	}
+}
+
+func WithKeySetHTTPClient(client *http.Client) keySetOption {
+	return func(keySet *KeySet) {
+		keySet.client = client
+	}
+}
+
+// Key returns the key named kid, an empty kid matches the only key of a set
+// holding one. alg is checked against the algorithm of the key when the key
+// declares one.
+func (s *KeySet) Key(ctx context.Context, kid, alg string) (interface{}, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+
+	_, known := s.lookup(kid)
+	stale := now.Sub(s.fetchedAt) >= s.ttl || !known
+	if s.keys == nil || (stale && now.Sub(s.attemptedAt) >= s.refreshInterval) {
+		s.attemptedAt = now
+		keys, err := s.fetch(ctx)
+		// on failure the previous keys are kept until the next attempt
+		if err != nil && s.keys == nil {
+			return nil, err
+		}
+		if err == nil {
+			s.keys, s.fetchedAt = keys, now
+		}
+	}
+
+	key, ok := s.lookup(kid)
+	if !ok {
+		return nil, ErrUnknownKey
+	}
+
+	if key.alg != "" && key.alg != alg {
+		return nil, fmt.Errorf("%w: key %q is for %s, not %s", ErrUnknownKey, kid, key.alg, alg)
+	}
+
+	return key.key, nil
+}
+
+func (s *KeySet) lookup(kid string) (jwk, bool) {
+
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+
+	key, ok := s.keys[kid]
+
+	return key, ok
+}
+
+func (s *KeySet) fetch(ctx context.Context) (map[string]jwk, error) {
+
+	var content []byte
+
+	if strings.HasPrefix(s.source, "http://") || strings.HasPrefix(s.source, "https://") {
+		request, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		response, err := s.client.Do(request)
+		if err != nil {
+			return nil, err
+		}
+		defer response.Body.Close()
+
+		if response.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("fetching %s: %s", s.source, response.Status)
+		}
+
+		if content, err = io.ReadAll(io.LimitReader(response.Body, maxKeySetSize)); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		if content, err = os.ReadFile(s.source); err != nil {
+			return nil, err
+		}
+	}
+
+	return parseKeySet(content)
+}
+
+func parseKeySet(content []byte) (map[string]jwk, error) {
+
+	var set struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+
+	if err := json.Unmarshal(content, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]jwk, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		key, err := k.parse()
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+
+		keys[k.Kid] = jwk{alg: k.Alg, key: key}
+	}
+
+	return keys, nil
+}
+
+func (k jsonWebKey) parse() (interface{}, error) {
+
+	switch k.Kty {
+	case "RSA":
+		n, err := decodeBigInt(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+
+	case "EC":
+		if k.Crv != "P-256" {
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := decodeBigInt(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}
+		if !key.Curve.IsOnCurve(x, y) {
+			return nil, errors.New("point is not on the curve")
+		}
+		return key, nil
+
+	case "oct":
+		secret, err := base64.RawURLEncoding.DecodeString(k.K)
+		if err != nil {
+			return nil, err
+		}
+		return secret, nil
+	}
+
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(raw) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+
+	return new(big.Int).SetBytes(raw), nil
+}
@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type (
+	// JWTVerifier validates bearer tokens signed with RS256, ES256 or HS256 by
+	// a key of a KeySet and maps the roles they claim to scopes.
+	JWTVerifier struct {
+		keys        *KeySet
+		parser      *jwt.Parser
+		rolesClaim  string
+		tenantClaim string
+		roles       map[string][]Scope
+		issuer      string
+		audience    string
+		leeway      time.Duration
+	}
+
+	jwtOption func(verifier *JWTVerifier)
+)
+
+var (
+	ErrInvalidToken = errors.New("invalid bearer token")
+
+	// DefaultRoles maps the roles of a token to scopes when no other mapping
+	// is configured.
+	DefaultRoles = map[string][]Scope{
+		"reader": {ScopeRead},
+		"editor": {ScopeRead, ScopeWrite},
+		"admin":  {ScopeAdmin},
+	}
+)
+
+func NewJWTVerifier(keys *KeySet, opts ...jwtOption) *JWTVerifier {
+	verifier := &JWTVerifier{
+		keys:        keys,
+		rolesClaim:  "roles",
+		tenantClaim: "tenant",
+		roles:       DefaultRoles,
+		leeway:      30 * time.Second,
+	}
+
+	for i := range opts {
+		opts[i](verifier)
+	}
+
+	parserOptions := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{"RS256", "ES256", "HS256"}),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(verifier.leeway),
+	}
+	if verifier.issuer != "" {
+		parserOptions = append(parserOptions, jwt.WithIssuer(verifier.issuer))
+	}
+	if verifier.audience != "" {
+		parserOptions = append(parserOptions, jwt.WithAudience(verifier.audience))
+	}
+
+	verifier.parser = jwt.NewParser(parserOptions...)
+
+	return verifier
+}
+
+// WithIssuer rejects the tokens not issued by issuer.
+func WithIssuer(issuer string) jwtOption {
+	return func(verifier *JWTVerifier) {
+		verifier.issuer = issuer
+	}
+}
+
+// WithAudience rejects the tokens not issued for audience.
+func WithAudience(audience string) jwtOption {
+	return func(verifier *JWTVerifier) {
+		verifier.audience = audience
+	}
+}
+
+// WithRolesClaim sets the claim holding the roles, a dotted path such as
+// realm_access.roles reaches into nested claims.
+func WithRolesClaim(claim string) jwtOption {
+	return func(verifier *JWTVerifier) {
+		verifier.rolesClaim = claim
+	}
+}
+
+// WithTenantClaim sets the claim, a dotted path like the roles claim, binding
+// the principal of a token to a tenant.
+func WithTenantClaim(claim string) jwtOption {
+	return func(verifier *JWTVerifier) {
+		verifier.tenantClaim = claim
+	}
+}
+
+// WithRoles sets the scopes granted by each role, the roles missing from
+// roles grant nothing.
+func WithRoles(roles map[string][]Scope) jwtOption {
+	return func(verifier *JWTVerifier) {
+		verifier.roles = roles
+	}
+}
+
+// WithLeeway sets the clock skew tolerated on the time claims.
+func WithLeeway(leeway time.Duration) jwtOption {
+	return func(verifier *JWTVerifier) {
+		verifier.leeway = leeway
+	}
+}
+
+// Verify returns the principal token authenticates, errors caused by the
+// token wrap ErrInvalidToken.
+func (v *JWTVerifier) Verify(ctx context.Context, token string) (*Principal, error) {
+
+	var keyErr error
+
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := v.keys.Key(ctx, kid, t.Method.Alg())
+		if err != nil && !errors.Is(err, ErrUnknownKey) {
+			keyErr = err
+		}
+		return key, err
+	})
+
+	// the key set could not be fetched, the token may well be valid
+	if keyErr != nil {
+		return nil, keyErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	subject, _ := claims.GetSubject()
+
+	principal := &Principal{Subject: subject, Label: jwtLabel}
+	if tenants := claimStrings(claims, v.tenantClaim); len(tenants) == 1 {
+		principal.Tenant = tenants[0]
+	}
+	for _, role := range claimStrings(claims, v.rolesClaim) {
+		principal.Roles = append(principal.Roles, role)
+		principal.Scopes = append(principal.Scopes, v.roles[role]...)
+	}
+
+	return principal, nil
+}
+
+// claimStrings returns the strings of the claim at path, a claim can be a
+// list of strings or a space separated string.
+func claimStrings(claims map[string]interface{}, path string) []string {
+
+	var value interface{} = claims
+	for _, name := range strings.Split(path, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[name]
+	}
+
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+
+	return nil
+}
@@ -0,0 +1,189 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+func encode(i *big.Int) string {
+	return base64.RawURLEncoding.EncodeToString(i.Bytes())
+}
+
+func TestAuthorizeBearerTokens(t *testing.T) {
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret := []byte("a-shared-secret-of-32-bytes-long")
+
+	set, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa", "alg": "RS256", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+		{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+		{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": base64.RawURLEncoding.EncodeToString(secret)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, set, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := NewJWTVerifier(NewKeySet(path), WithIssuer("https://sso.example.com"))
+	authenticator := NewAuthenticator(slog.New(slog.NewTextHandler(io.Discard, nil)), &memoryKeyStore{keys: map[string]*APIKey{}}, WithJWT(verifier))
+
+	router := mux.NewRouter()
+	router.Use(authenticator.Authorize(Permissions{"listPeople": ScopeRead, "deletePerson": ScopeWrite}))
+	router.HandleFunc("/people", func(http.ResponseWriter, *http.Request) {}).Methods(http.MethodGet).Name("listPeople")
+	router.HandleFunc("/person/{id}", func(http.ResponseWriter, *http.Request) {}).Methods(http.MethodDelete).Name("deletePerson")
+	router.HandleFunc("/unlisted", func(http.ResponseWriter, *http.Request) {}).Name("unlisted")
+
+	sign := func(method jwt.SigningMethod, kid string, key interface{}, roles []string, expiresIn time.Duration) string {
+		token := jwt.NewWithClaims(method, jwt.MapClaims{
+			"sub":   "david",
+			"iss":   "https://sso.example.com",
+			"exp":   time.Now().Add(expiresIn).Unix(),
+			"roles": roles,
+		})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	reader := sign(jwt.SigningMethodRS256, "rsa", rsaKey, []string{"reader"}, time.Hour)
+
+	tests := []struct {
+		name   string
+		method string
+		path   string
+		token  string
+		status int
+	}{
+		{"no token", http.MethodGet, "/people", "", http.StatusUnauthorized},
+		{"garbage", http.MethodGet, "/people", "not-a-jwt", http.StatusUnauthorized},
+		{"expired", http.MethodGet, "/people", sign(jwt.SigningMethodRS256, "rsa", rsaKey, []string{"reader"}, -time.Hour), http.StatusUnauthorized},
+		{"unknown kid", http.MethodGet, "/people", sign(jwt.SigningMethodRS256, "other", rsaKey, []string{"reader"}, time.Hour), http.StatusUnauthorized},
+		{"algorithm of another key", http.MethodGet, "/people", sign(jwt.SigningMethodHS256, "rsa", secret, []string{"reader"}, time.Hour), http.StatusUnauthorized},
+		{"RS256 reader reads", http.MethodGet, "/people", reader, http.StatusOK},
+		{"RS256 reader deletes", http.MethodDelete, "/person/1", reader, http.StatusForbidden},
+		{"ES256 editor deletes", http.MethodDelete, "/person/1", sign(jwt.SigningMethodES256, "ec", ecKey, []string{"editor"}, time.Hour), http.StatusOK},
+		{"HS256 editor reads", http.MethodGet, "/people", sign(jwt.SigningMethodHS256, "hmac", secret, []string{"editor"}, time.Hour), http.StatusOK},
+		{"route without permission", http.MethodGet, "/unlisted", reader, http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(test.method, test.path, nil)
+			if test.token != "" {
+				request.Header.Set("Authorization", "Bearer "+test.token)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("expected %d, got %d: %s", test.status, recorder.Code, recorder.Body)
+			}
+		})
+	}
+}
+
+func TestAdminTokenWithBearerTokens(t *testing.T) {
+
+	secret := []byte("a-shared-secret-of-32-bytes-long")
+	set, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+		{"kty": "oct", "kid": "hmac", "alg": "HS256", "k": base64.RawURLEncoding.EncodeToString(secret)},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, set, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	authenticator := NewAuthenticator(logger, &memoryKeyStore{keys: map[string]*APIKey{}}, WithJWT(NewJWTVerifier(NewKeySet(path))))
+
+	_, adminKey, err := authenticator.Issue(context.Background(), "admin", []Scope{ScopeAdmin}, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"sub":   "david",
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	})
+	token.Header["kid"] = "hmac"
+	adminJWT, err := token.SignedString(secret)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.Use(RequireToken(logger, "s3cret"))
+	router.Handle("/admin/keys", authenticator.Require(ScopeAdmin)(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {})))
+
+	tests := []struct {
+		name       string
+		adminToken string
+		apiKey     string
+		bearer     string
+		status     int
+	}{
+		{"admin key", "s3cret", adminKey, "", http.StatusOK},
+		{"admin bearer token", "s3cret", "", adminJWT, http.StatusOK},
+		{"missing admin token", "", adminKey, "", http.StatusUnauthorized},
+		{"admin token alone", "s3cret", "", "", http.StatusUnauthorized},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/admin/keys", nil)
+			if test.adminToken != "" {
+				request.Header.Set(AdminTokenHeader, test.adminToken)
+			}
+			if test.apiKey != "" {
+				request.Header.Set(APIKeyHeader, test.apiKey)
+			}
+			if test.bearer != "" {
+				request.Header.Set("Authorization", "Bearer "+test.bearer)
+			}
+
+			recorder := httptest.NewRecorder()
+			router.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("expected %d, got %d: %s", test.status, recorder.Code, recorder.Body)
+			}
+		})
+	}
+}
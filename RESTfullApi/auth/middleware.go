@@ -0,0 +1,259 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/instrumentation"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// Authenticator checks the API key sent in the X-API-Key header or, when
+	// a JWTVerifier is set, the bearer token of the Authorization header.
+	Authenticator struct {
+		logger        *slog.Logger
+		store         KeyStore
+		jwt           *JWTVerifier
+		timeout       time.Duration
+		touchInterval time.Duration
+		now           func() time.Time
+
+		// touched holds when each key was last touched, so that the requests
+		// of a busy key do not all update it before the first update lands
+		touchMu sync.Mutex
+		touched map[primitive.ObjectID]time.Time
+	}
+
+	option func(authenticator *Authenticator)
+)
+
+const (
+	APIKeyHeader = "X-API-Key"
+
+	setContentType         = "content-type"
+	jsonType               = "application/json"
+	wwwAuthenticateHeader  = "WWW-Authenticate"
+	authorizationHeader    = "Authorization"
+	apiKeyChallenge        = `APIKey realm="people", header="` + APIKeyHeader + `"`
+	bearerChallenge        = `Bearer realm="people"`
+	errorMissingPermission = "No permission is configured for the route, it is restricted to admins"
+	errorAuthenticating    = "Error while authenticating a request"
+	errorTouchingKey       = "Error while updating the last use of an API key"
+	errorWrittingResponse  = "Error while writing the error response"
+	insufficientScope      = "the caller lacks the %s scope"
+	authenticationDown     = "authentication is unavailable"
+)
+
+var errMissingCredentials = errors.New("missing API key or bearer token")
+
+func NewAuthenticator(logger *slog.Logger, store KeyStore, opts ...option) *Authenticator {
+	authenticator := &Authenticator{
+		logger:        logger,
+		store:         store,
+		timeout:       5 * time.Second,
+		touchInterval: time.Minute,
+		now:           time.Now,
+		touched:       map[primitive.ObjectID]time.Time{},
+	}
+
+	for i := range opts {
+		opts[i](authenticator)
+	}
+
+	return authenticator
+}
+
+func WithTimeout(timeout time.Duration) option {
+	return func(authenticator *Authenticator) {
+		authenticator.timeout = timeout
+	}
+}
+
+// WithJWT accepts the bearer tokens verified by verifier along with API keys,
+// a nil verifier only accepts API keys.
+func WithJWT(verifier *JWTVerifier) option {
+	return func(authenticator *Authenticator) {
+		authenticator.jwt = verifier
+	}
+}
+
+// WithTouchInterval sets how stale the last used timestamp of a key can get,
+// so that a busy key is not written on every request.
+func WithTouchInterval(interval time.Duration) option {
+	return func(authenticator *Authenticator) {
+		authenticator.touchInterval = interval
+	}
+}
+
+// Require rejects the requests without valid credentials with 401 and those
+// whose principal lacks scope with 403.
+func (a *Authenticator) Require(scope Scope) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			a.serve(scope, next, response, request)
+		})
+	}
+}
+
+func (a *Authenticator) serve(scope Scope, next http.Handler, response http.ResponseWriter, request *http.Request) {
+
+	principal, err := a.authenticateRequest(request.Context(), request.Header.Get(APIKeyHeader), request.Header.Get(authorizationHeader))
+	if err != nil {
+		a.authenticationError(response, request, err)
+		return
+	}
+
+	instrumentation.SetKeyLabel(request, principal.Label)
+
+	if !principal.HasScope(scope) {
+		if principal.Key == nil {
+			response.Header().Set(wwwAuthenticateHeader, `Bearer realm="people", error="insufficient_scope", scope="`+string(scope)+`"`)
+		}
+		a.writeError(response, request, http.StatusForbidden, fmt.Sprintf(insufficientScope, scope))
+		return
+	}
+
+	next.ServeHTTP(response, request.WithContext(NewContext(request.Context(), principal)))
+}
+
+// authenticateRequest authenticates the bearer token of the authorization
+// value when bearer tokens are accepted, the API key otherwise.
+func (a *Authenticator) authenticateRequest(ctx context.Context, apiKey, authorization string) (*Principal, error) {
+
+	if token, ok := cutBearer(authorization); ok && a.jwt != nil {
+		ctx, cancel := context.WithTimeout(ctx, a.timeout)
+		defer cancel()
+		return a.jwt.Verify(ctx, token)
+	}
+
+	if apiKey == "" {
+		return nil, errMissingCredentials
+	}
+
+	key, err := a.Authenticate(ctx, apiKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeyPrincipal(key), nil
+}
+
+func cutBearer(authorization string) (string, bool) {
+
+	scheme, token, ok := strings.Cut(authorization, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+
+	return token, token != ""
+}
+
+// Authenticate returns the valid key matching secret.
+func (a *Authenticator) Authenticate(ctx context.Context, secret string) (*APIKey, error) {
+
+	ctx, cancel := context.WithTimeout(ctx, a.timeout)
+	defer cancel()
+
+	key, err := a.store.FindByHash(ctx, HashKey(secret))
+	if err != nil {
+		return nil, err
+	}
+
+	now := a.now()
+
+	if err := key.Valid(now); err != nil {
+		return nil, err
+	}
+
+	if a.shouldTouch(key, now) {
+		go a.touch(key, now)
+	}
+
+	return key, nil
+}
+
+// shouldTouch reports whether the last use of key is stale, it records now as
+// the last touch of key so that only one update is started per interval.
+func (a *Authenticator) shouldTouch(key *APIKey, now time.Time) bool {
+
+	a.touchMu.Lock()
+	defer a.touchMu.Unlock()
+
+	lastUsed := key.LastUsedAt
+	if touched, ok := a.touched[key.ID]; ok && (lastUsed == nil || touched.After(*lastUsed)) {
+		lastUsed = &touched
+	}
+	if lastUsed != nil && now.Sub(*lastUsed) < a.touchInterval {
+		return false
+	}
+
+	// the stale entries are no longer needed, the store has them or their
+	// update failed and may be retried
+	for id, touched := range a.touched {
+		if now.Sub(touched) >= a.touchInterval {
+			delete(a.touched, id)
+		}
+	}
+	a.touched[key.ID] = now
+
+	return true
+}
+
+func (a *Authenticator) touch(key *APIKey, now time.Time) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), a.timeout)
+	defer cancel()
+
+	if err := a.store.Touch(ctx, key.ID, now); err != nil {
+		a.logger.WarnContext(ctx, errorTouchingKey, "prefix", key.Prefix, "error", err)
+	}
+}
+
+func (a *Authenticator) authenticationError(response http.ResponseWriter, request *http.Request, err error) {
+
+	if isKeyError(err) {
+		a.unauthorized(response, request, err.Error())
+		return
+	}
+
+	// the key store is unavailable, the caller is not at fault
+	a.logger.ErrorContext(request.Context(), errorAuthenticating, "error", err)
+	a.writeError(response, request, http.StatusServiceUnavailable, authenticationDown)
+}
+
+func (a *Authenticator) unauthorized(response http.ResponseWriter, request *http.Request, message string) {
+
+	response.Header().Add(wwwAuthenticateHeader, apiKeyChallenge)
+	if a.jwt != nil {
+		response.Header().Add(wwwAuthenticateHeader, bearerChallenge)
+	}
+	a.writeError(response, request, http.StatusUnauthorized, message)
+}
+
+func (a *Authenticator) writeError(response http.ResponseWriter, request *http.Request, status int, message string) {
+
+	response.Header().Set(setContentType, jsonType)
+	response.WriteHeader(status)
+
+	if err := writeMessage(response, message); err != nil {
+		a.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+	}
+}
+
+// isKeyError reports whether err is the fault of the credentials rather than
+// of the key store or of the key set.
+func isKeyError(err error) bool {
+	return errors.Is(err, errMissingCredentials) || errors.Is(err, ErrInvalidKey) || errors.Is(err, ErrExpiredKey) ||
+		errors.Is(err, ErrRevokedKey) || errors.Is(err, ErrInvalidToken)
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type memoryKeyStore struct {
+	mu   sync.Mutex
+	keys map[string]*APIKey
+}
+
+func (s *memoryKeyStore) Create(_ context.Context, key *APIKey) (primitive.ObjectID, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key.ID = primitive.NewObjectID()
+	s.keys[key.Hash] = key
+	return key.ID, nil
+}
+
+func (s *memoryKeyStore) FindByHash(_ context.Context, hash string) (*APIKey, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key, ok := s.keys[hash]
+	if !ok {
+		return nil, ErrInvalidKey
+	}
+	copied := *key
+	return &copied, nil
+}
+
+func (s *memoryKeyStore) List(context.Context) ([]*APIKey, error) { return nil, nil }
+
+func (s *memoryKeyStore) Touch(context.Context, primitive.ObjectID, time.Time) error { return nil }
+
+func (s *memoryKeyStore) Revoke(_ context.Context, id primitive.ObjectID, at time.Time) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range s.keys {
+		if key.ID == id && key.RevokedAt == nil {
+			key.RevokedAt = &at
+			return 1, nil
+		}
+	}
+	return 0, nil
+}
+
+func TestRequire(t *testing.T) {
+
+	keys := &memoryKeyStore{keys: map[string]*APIKey{}}
+	authenticator := NewAuthenticator(slog.New(slog.NewTextHandler(io.Discard, nil)), keys)
+
+	ctx := context.Background()
+
+	_, reader, err := authenticator.Issue(ctx, "reader", []Scope{ScopeRead}, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, expired, err := authenticator.Issue(ctx, "expired", []Scope{ScopeRead}, time.Nanosecond, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	revokedKey, revoked, err := authenticator.Issue(ctx, "revoked", []Scope{ScopeAdmin}, 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := keys.Revoke(ctx, revokedKey.ID, time.Now()); err != nil {
+		t.Fatal(err)
+	}
+
+	handler := func(scope Scope) http.Handler {
+		return authenticator.Require(scope)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if key, ok := KeyFromContext(r.Context()); !ok || key.Name != "reader" {
+				t.Errorf("unexpected key in context %+v", key)
+			}
+		}))
+	}
+
+	tests := []struct {
+		name   string
+		key    string
+		scope  Scope
+		status int
+	}{
+		{"missing key", "", ScopeRead, http.StatusUnauthorized},
+		{"unknown key", "pk_unknown", ScopeRead, http.StatusUnauthorized},
+		{"expired key", expired, ScopeRead, http.StatusUnauthorized},
+		{"revoked key", revoked, ScopeRead, http.StatusUnauthorized},
+		{"missing scope", reader, ScopeWrite, http.StatusForbidden},
+		{"granted scope", reader, ScopeRead, http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v2/people", nil)
+			if test.key != "" {
+				request.Header.Set(APIKeyHeader, test.key)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler(test.scope).ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("expected %d, got %d: %s", test.status, recorder.Code, recorder.Body)
+			}
+		})
+	}
+}
+
+func TestTouchOnce(t *testing.T) {
+
+	authenticator := NewAuthenticator(slog.New(slog.NewTextHandler(io.Discard, nil)), &memoryKeyStore{keys: map[string]*APIKey{}})
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	key := &APIKey{ID: primitive.NewObjectID()}
+
+	var (
+		wg      sync.WaitGroup
+		mu      sync.Mutex
+		touches int
+	)
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if authenticator.shouldTouch(key, now) {
+				mu.Lock()
+				touches++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if touches != 1 {
+		t.Fatalf("expected 1 touch, got %d", touches)
+	}
+	if authenticator.shouldTouch(key, now.Add(time.Second)) {
+		t.Error("expected no touch within the interval")
+	}
+	if !authenticator.shouldTouch(key, now.Add(time.Minute)) {
+		t.Error("expected a touch once the interval elapsed")
+	}
+}
+
+func TestRequireToken(t *testing.T) {
+
+	handler := RequireToken(slog.New(slog.NewTextHandler(io.Discard, nil)), "s3cret", "/healthz")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	tests := []struct {
+		name   string
+		path   string
+		token  string
+		status int
+	}{
+		{"valid token", "/metrics", "s3cret", http.StatusOK},
+		{"missing token", "/metrics", "", http.StatusUnauthorized},
+		{"wrong token", "/metrics", "s3cre", http.StatusUnauthorized},
+		{"exempt path", "/healthz", "", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.token != "" {
+				request.Header.Set(AdminTokenHeader, test.token)
+			}
+
+			response := httptest.NewRecorder()
+			handler.ServeHTTP(response, request)
+
+			if response.Code != test.status {
+				t.Errorf("expected %d, got %d", test.status, response.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+type (
+	// Principal is the caller a request was authenticated as, either with an
+	// API key or with a bearer token.
+	Principal struct {
+		// Subject is the key id or the sub claim of the token.
+		Subject string
+		// Label names the principal in the metrics, the name of an API key
+		// or "jwt" to keep their cardinality bounded.
+		Label  string
+		Roles  []string
+		Scopes []Scope
+		// Tenant is the tenant the principal is bound to, empty when it can
+		// act on any tenant.
+		Tenant string
+		// Key is the API key of the principal, nil for bearer tokens.
+		Key *APIKey
+	}
+
+	// Permissions maps the names of the routes to the scope they require.
+	Permissions map[string]Scope
+
+	keyPrincipal struct{}
+)
+
+const jwtLabel = "jwt"
+
+func newKeyPrincipal(key *APIKey) *Principal {
+	return &Principal{Subject: key.ID.Hex(), Label: key.Name, Scopes: key.Scopes, Tenant: key.Tenant, Key: key}
+}
+
+// HasScope reports whether the principal was granted scope, admin grants
+// every scope.
+func (p *Principal) HasScope(scope Scope) bool {
+	return grants(p.Scopes, scope)
+}
+
+func grants(scopes []Scope, scope Scope) bool {
+
+	for _, s := range scopes {
+		if s == scope || s == ScopeAdmin {
+			return true
+		}
+	}
+
+	return false
+}
+
+// NewContext returns a copy of ctx carrying principal.
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, keyPrincipal{}, principal)
+}
+
+// PrincipalFromContext returns the principal a request was authenticated as.
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+
+	principal, ok := ctx.Value(keyPrincipal{}).(*Principal)
+
+	return principal, ok
+}
+
+// KeyFromContext returns the API key a request was authenticated with.
+func KeyFromContext(ctx context.Context) (*APIKey, bool) {
+
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || principal.Key == nil {
+		return nil, false
+	}
+
+	return principal.Key, true
+}
+
+// HasScope reports whether the request of ctx was authenticated as a
+// principal granted scope.
+func HasScope(ctx context.Context, scope Scope) bool {
+
+	principal, ok := PrincipalFromContext(ctx)
+
+	return ok && principal.HasScope(scope)
+}
+
+// Authorize requires the scope permissions maps the name of the matched route
+// to. Routes missing from permissions are refused to everybody but admins,
+// so that a route added without a permission is not left open.
+func (a *Authenticator) Authorize(permissions Permissions) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+			scope, ok := permissions[routeName(request)]
+			if !ok {
+				a.logger.WarnContext(request.Context(), errorMissingPermission, "route", routeName(request))
+				scope = ScopeAdmin
+			}
+
+			a.serve(scope, next, response, request)
+		})
+	}
+}
+
+func routeName(request *http.Request) string {
+
+	if route := mux.CurrentRoute(request); route != nil {
+		return route.GetName()
+	}
+
+	return ""
+}
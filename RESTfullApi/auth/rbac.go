@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// RBAC holds the scopes granted by the roles of bearer tokens and the scope
+// required by each route, as read from a file such as:
+//
+//	roles:
+//	  people-viewer: [people:read]
+//	  people-admin: [admin]
+//	permissions:
+//	  listPeople: people:read
+//	  deletePerson: admin
+type RBAC struct {
+	Roles       map[string][]Scope `yaml:"roles"`
+	Permissions Permissions        `yaml:"permissions"`
+}
+
+// LoadRBAC reads the file at path over defaults, the roles and permissions
+// of the file replace or add to those of defaults.
+func LoadRBAC(path string, defaults RBAC) (RBAC, error) {
+
+	rbac := RBAC{Roles: map[string][]Scope{}, Permissions: Permissions{}}
+	for role, scopes := range defaults.Roles {
+		rbac.Roles[role] = scopes
+	}
+	for route, scope := range defaults.Permissions {
+		rbac.Permissions[route] = scope
+	}
+
+	if path == "" {
+		return rbac, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return RBAC{}, err
+	}
+
+	var file RBAC
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return RBAC{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	for role, scopes := range file.Roles {
+		if _, err := validScopes(scopes); err != nil {
+			return RBAC{}, fmt.Errorf("role %q: %w", role, err)
+		}
+		rbac.Roles[role] = scopes
+	}
+
+	for route, scope := range file.Permissions {
+		if _, err := validScopes([]Scope{scope}); err != nil {
+			return RBAC{}, fmt.Errorf("route %q: %w", route, err)
+		}
+		rbac.Permissions[route] = scope
+	}
+
+	return rbac, nil
+}
@@ -0,0 +1,114 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// KeyStore persists API keys.
+	KeyStore interface {
+		Create(ctx context.Context, key *APIKey) (primitive.ObjectID, error)
+		// FindByHash returns ErrInvalidKey when no key has the hash.
+		FindByHash(ctx context.Context, hash string) (*APIKey, error)
+		List(ctx context.Context) ([]*APIKey, error)
+		Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error
+		// Revoke returns the number of keys revoked, 0 when the key does not
+		// exist or was already revoked.
+		Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) (int64, error)
+	}
+
+	MongoKeyStore struct {
+		collection *mongo.Collection
+	}
+)
+
+const (
+	hashKey       = "hash"
+	lastUsedAtKey = "lastUsedAt"
+	revokedAtKey  = "revokedAt"
+	setCommand    = "$set"
+	existsCommand = "$exists"
+)
+
+func NewMongoKeyStore(collection *mongo.Collection) *MongoKeyStore {
+	return &MongoKeyStore{collection: collection}
+}
+
+// EnsureIndexes creates the unique index keys are looked up by.
+func (s *MongoKeyStore) EnsureIndexes(ctx context.Context) error {
+
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: hashKey, Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+func (s *MongoKeyStore) Create(ctx context.Context, key *APIKey) (primitive.ObjectID, error) {
+
+	result, err := s.collection.InsertOne(ctx, key)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	id, _ := result.InsertedID.(primitive.ObjectID)
+
+	return id, nil
+}
+
+func (s *MongoKeyStore) FindByHash(ctx context.Context, hash string) (*APIKey, error) {
+
+	var key APIKey
+
+	err := s.collection.FindOne(ctx, bson.M{hashKey: hash}).Decode(&key)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrInvalidKey
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &key, nil
+}
+
+func (s *MongoKeyStore) List(ctx context.Context) ([]*APIKey, error) {
+
+	cursor, err := s.collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	keys := []*APIKey{}
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+func (s *MongoKeyStore) Touch(ctx context.Context, id primitive.ObjectID, at time.Time) error {
+
+	_, err := s.collection.UpdateByID(ctx, id, bson.M{setCommand: bson.M{lastUsedAtKey: at.UTC()}})
+
+	return err
+}
+
+func (s *MongoKeyStore) Revoke(ctx context.Context, id primitive.ObjectID, at time.Time) (int64, error) {
+
+	result, err := s.collection.UpdateOne(ctx,
+		bson.M{"_id": id, revokedAtKey: bson.M{existsCommand: false}},
+		bson.M{setCommand: bson.M{revokedAtKey: at.UTC()}},
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
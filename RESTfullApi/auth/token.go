@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// AdminTokenHeader carries the token of the admin listener, apart from
+	// the Authorization header so that a bearer token can still authenticate
+	// the caller.
+	AdminTokenHeader = "X-Admin-Token"
+
+	adminChallenge = `AdminToken realm="admin", header="` + AdminTokenHeader + `"`
+	invalidToken   = "missing or invalid admin token"
+)
+
+// RequireToken rejects with 401 the requests without token in their
+// X-Admin-Token header, but those to the paths of exempt, such as the probes
+// of an orchestrator. An empty token requires none.
+func RequireToken(logger *slog.Logger, token string, exempt ...string) mux.MiddlewareFunc {
+
+	expected := []byte(token)
+
+	return func(next http.Handler) http.Handler {
+
+		if token == "" {
+			return next
+		}
+
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+			for _, path := range exempt {
+				if request.URL.Path == path {
+					next.ServeHTTP(response, request)
+					return
+				}
+			}
+
+			got := []byte(strings.TrimSpace(request.Header.Get(AdminTokenHeader)))
+			if subtle.ConstantTimeCompare(got, expected) == 1 {
+				next.ServeHTTP(response, request)
+				return
+			}
+
+			response.Header().Set(wwwAuthenticateHeader, adminChallenge)
+			response.Header().Set(setContentType, jsonType)
+			response.WriteHeader(http.StatusUnauthorized)
+
+			if err := writeMessage(response, invalidToken); err != nil {
+				logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+			}
+		})
+	}
+}
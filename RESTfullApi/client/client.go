@@ -0,0 +1,401 @@
+// Package client is a typed Go client for the /v2 people API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+)
+
+type (
+	Client struct {
+		baseURL    *url.URL
+		httpClient *http.Client
+		retries    int
+		backoff    time.Duration
+		maxBackoff time.Duration
+		userAgent  string
+		apiKey     string
+		token      string
+	}
+
+	option func(client *Client)
+
+	ListOptions struct {
+		// PageSize is the number of people fetched per request, defaults to 100.
+		PageSize int
+	}
+
+	Page struct {
+		People data.People
+		// Next is the cursor of the following page, empty on the last page.
+		Next string
+	}
+
+	// PageIterator walks the pages of a List call:
+	//
+	//	it := c.List(ctx, client.ListOptions{})
+	//	for it.Next() {
+	//		for _, person := range it.Page().People { ... }
+	//	}
+	//	if err := it.Err(); err != nil { ... }
+	PageIterator struct {
+		ctx    context.Context
+		client *Client
+		opts   ListOptions
+		page   *Page
+		err    error
+		done   bool
+	}
+)
+
+const (
+	apiPrefix         = "/v2"
+	defaultPageSize   = 100
+	retryAfterHeader  = "Retry-After"
+	contentTypeHeader = "Content-Type"
+	apiKeyHeader      = "X-API-Key"
+	requestIDHeader   = "X-Request-ID"
+	timeoutHeader     = "X-Request-Timeout"
+	jsonType          = "application/json"
+)
+
+// New returns a client for the API served at baseURL, for example
+// http://localhost:8080.
+func New(baseURL string, opts ...option) (*Client, error) {
+
+	u, err := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base url %q", baseURL)
+	}
+
+	client := &Client{
+		baseURL:    u,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		retries:    3,
+		backoff:    100 * time.Millisecond,
+		maxBackoff: 5 * time.Second,
+		userAgent:  "people-go-client",
+	}
+
+	for i := range opts {
+		opts[i](client)
+	}
+
+	return client, nil
+}
+
+func WithHTTPClient(httpClient *http.Client) option {
+	return func(client *Client) {
+		client.httpClient = httpClient
+	}
+}
+
+// WithRetries sets how many times a request is retried after a 5xx or 429
+// response, zero disables retries.
+func WithRetries(retries int) option {
+	return func(client *Client) {
+		client.retries = retries
+	}
+}
+
+// WithBackoff sets the delay before the first retry, doubled on every
+// further retry up to max.
+func WithBackoff(initial, max time.Duration) option {
+	return func(client *Client) {
+		client.backoff = initial
+		client.maxBackoff = max
+	}
+}
+
+func WithUserAgent(userAgent string) option {
+	return func(client *Client) {
+		client.userAgent = userAgent
+	}
+}
+
+// WithAPIKey sends key in the X-API-Key header of every request.
+func WithAPIKey(key string) option {
+	return func(client *Client) {
+		client.apiKey = key
+	}
+}
+
+// WithBearerToken sends token in the Authorization header of every request.
+func WithBearerToken(token string) option {
+	return func(client *Client) {
+		client.token = token
+	}
+}
+
+// Get returns the person with the given id, the error matches ErrNotFound
+// when there is none.
+func (c *Client) Get(ctx context.Context, id string) (*data.Person, error) {
+
+	var dto data.PersonV2
+
+	if err := c.do(ctx, http.MethodGet, "/person/"+url.PathEscape(id), nil, nil, &dto); err != nil {
+		return nil, err
+	}
+
+	person, err := dto.ToPerson()
+	if err != nil {
+		return nil, err
+	}
+
+	return &person, nil
+}
+
+// List returns an iterator over the pages of people, ordered by id.
+func (c *Client) List(ctx context.Context, opts ListOptions) *PageIterator {
+
+	if opts.PageSize <= 0 {
+		opts.PageSize = defaultPageSize
+	}
+
+	return &PageIterator{ctx: ctx, client: c, opts: opts}
+}
+
+// Create stores person and returns it with its id.
+func (c *Client) Create(ctx context.Context, person data.Person) (*data.Person, error) {
+
+	var created data.PersonV2
+
+	if err := c.do(ctx, http.MethodPost, "/person", nil, data.NewPersonV2(&person), &created); err != nil {
+		return nil, err
+	}
+
+	result, err := created.ToPerson()
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// Update sets the non empty fields of update on the person with the given id.
+func (c *Client) Update(ctx context.Context, id string, update data.PersonUpdate) error {
+
+	body := &data.PersonUpdateV2{
+		FirstName: update.Firstname,
+		LastName:  update.Lastname,
+	}
+
+	return c.do(ctx, http.MethodPut, "/person/"+url.PathEscape(id), nil, body, nil)
+}
+
+// Delete removes the person with the given id, the error matches ErrNotFound
+// when there is none.
+func (c *Client) Delete(ctx context.Context, id string) error {
+
+	return c.do(ctx, http.MethodDelete, "/person/"+url.PathEscape(id), nil, nil, nil)
+}
+
+// Search returns the people whose first name contains name as a whole word.
+func (c *Client) Search(ctx context.Context, name string) (data.People, error) {
+
+	people, err := c.listPage(ctx, "/personName/"+url.PathEscape(name), nil)
+
+	if err == nil {
+		return people.People, nil
+	}
+
+	if errors.Is(err, ErrNotFound) {
+		return data.People{}, nil
+	}
+
+	return nil, err
+}
+
+// Next fetches the following page, it returns false when there are no more
+// pages or an error occurred.
+func (it *PageIterator) Next() bool {
+
+	if it.done {
+		return false
+	}
+
+	query := url.Values{"limit": {strconv.Itoa(it.opts.PageSize)}}
+	if it.page != nil {
+		query.Set("after", it.page.Next)
+	}
+
+	page, err := it.client.listPage(it.ctx, "/people", query)
+	if err != nil {
+		it.err = err
+		it.done = true
+		return false
+	}
+
+	it.page = page
+	it.done = page.Next == ""
+
+	return len(page.People) > 0 || !it.done
+}
+
+func (it *PageIterator) Page() *Page {
+	return it.page
+}
+
+func (it *PageIterator) Err() error {
+	return it.err
+}
+
+func (c *Client) listPage(ctx context.Context, path string, query url.Values) (*Page, error) {
+
+	var dto data.PeopleV2
+
+	if err := c.do(ctx, http.MethodGet, path, query, nil, &dto); err != nil {
+		return nil, err
+	}
+
+	page := &Page{People: make(data.People, 0, len(dto.People)), Next: dto.Next}
+
+	for _, p := range dto.People {
+		person, err := p.ToPerson()
+		if err != nil {
+			return nil, err
+		}
+		page.People = append(page.People, &person)
+	}
+
+	return page, nil
+}
+
+// do sends the request, retrying 5xx and 429 responses. POST requests are
+// only retried on 429 as the server did not process them.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out interface{}) error {
+
+	var payload []byte
+
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+
+	// path is already escaped, keep it that way so ids and names can't add
+	// path segments.
+	u := *c.baseURL
+	u.RawPath = u.EscapedPath() + apiPrefix + path
+	u.Path, _ = url.PathUnescape(u.RawPath)
+	u.RawQuery = query.Encode()
+
+	for attempt := 0; ; attempt++ {
+
+		req, err := http.NewRequestWithContext(ctx, method, u.String(), bytes.NewReader(payload))
+		if err != nil {
+			return err
+		}
+
+		req.Header.Set("Accept", jsonType)
+		req.Header.Set("User-Agent", c.userAgent)
+		if payload != nil {
+			req.Header.Set(contentTypeHeader, jsonType)
+		}
+		if c.apiKey != "" {
+			req.Header.Set(apiKeyHeader, c.apiKey)
+		}
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		}
+		// the server gives up on the request along with the client
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) > 0 {
+			req.Header.Set(timeoutHeader, time.Until(deadline).Round(time.Millisecond).String())
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		if attempt < c.retries && retryable(method, resp.StatusCode) {
+			if err := sleep(ctx, c.delay(attempt, resp.Header.Get(retryAfterHeader))); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if resp.StatusCode < 200 || resp.StatusCode > 299 {
+			return newAPIError(resp.StatusCode, resp.Header.Get(requestIDHeader), respBody)
+		}
+
+		if out == nil {
+			return nil
+		}
+
+		return json.Unmarshal(respBody, out)
+	}
+}
+
+func retryable(method string, statusCode int) bool {
+
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return statusCode >= 500 && method != http.MethodPost
+}
+
+// delay honours Retry-After when the server sends it, otherwise it backs off
+// exponentially with jitter.
+func (c *Client) delay(attempt int, retryAfter string) time.Duration {
+
+	if seconds, err := strconv.Atoi(retryAfter); err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(retryAfter); err == nil {
+		return time.Until(date)
+	}
+
+	backoff := c.backoff << attempt
+	if backoff <= 0 || backoff > c.maxBackoff {
+		backoff = c.maxBackoff
+	}
+
+	if backoff <= 0 {
+		return 0
+	}
+
+	return backoff/2 + time.Duration(rand.Int63n(int64(backoff/2)+1))
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+
+	if d <= 0 {
+		return ctx.Err()
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,149 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	c, err := New(server.URL, WithBackoff(time.Millisecond, 5*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+func TestGet(t *testing.T) {
+
+	id := primitive.NewObjectID()
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/person/"+id.Hex() {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{ "message": "No Person was found" }`))
+			return
+		}
+		json.NewEncoder(w).Encode(data.PersonV2{ID: id.Hex(), FirstName: "David", LastName: "Hernandez"})
+	})
+
+	person, err := c.Get(context.Background(), id.Hex())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if person.ID != id || person.Firstname != "David" || person.Lastname != "Hernandez" {
+		t.Fatalf("unexpected person %+v", person)
+	}
+
+	_, err = c.Get(context.Background(), primitive.NewObjectID().Hex())
+
+	var apiErr *APIError
+	if !errors.Is(err, ErrNotFound) || !errors.As(err, &apiErr) || apiErr.Message != "No Person was found" {
+		t.Fatalf("expected a not found api error, got %v", err)
+	}
+}
+
+func TestRetries(t *testing.T) {
+
+	var calls int32
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		case 2:
+			w.Header().Set(retryAfterHeader, "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(`{ "message": "Person with id was deleted" }`))
+		}
+	})
+
+	if err := c.Delete(context.Background(), primitive.NewObjectID().Hex()); err != nil {
+		t.Fatal(err)
+	}
+
+	if calls != 3 {
+		t.Fatalf("expected 3 calls, got %d", calls)
+	}
+}
+
+func TestCreateIsNotRetriedOnServerErrors(t *testing.T) {
+
+	var calls int32
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		http.Error(w, "Internal Error", http.StatusInternalServerError)
+	})
+
+	_, err := c.Create(context.Background(), data.Person{Firstname: "David", Lastname: "Hernandez"})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusInternalServerError || apiErr.Message != "Internal Error" {
+		t.Fatalf("expected an internal error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestListPages(t *testing.T) {
+
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("unexpected limit %q", r.URL.Query().Get("limit"))
+		}
+
+		page := ids[:2]
+		if r.URL.Query().Get("after") == ids[1].Hex() {
+			page = ids[2:]
+		}
+
+		var people data.People
+		for _, id := range page {
+			people = append(people, &data.Person{ID: id, Firstname: "David", Lastname: "Hernandez"})
+		}
+
+		dto := data.NewPeopleV2(people)
+		if len(page) == 2 {
+			dto.Next = page[1].Hex()
+		}
+		dto.ToJSON(w)
+	})
+
+	it := c.List(context.Background(), ListOptions{PageSize: 2})
+
+	var got []primitive.ObjectID
+	for it.Next() {
+		for _, person := range it.Page().People {
+			got = append(got, person.ID)
+		}
+	}
+
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(ids) {
+		t.Fatalf("expected %d people, got %d", len(ids), len(got))
+	}
+}
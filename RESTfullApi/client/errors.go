@@ -0,0 +1,65 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+var (
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// APIError is returned for every non 2xx response. It matches ErrNotFound,
+// ErrConflict and ErrPreconditionFailed with errors.Is for the 404, 409 and
+// 412 statuses. RequestID is the id the API logged the request with.
+type APIError struct {
+	StatusCode int
+	Message    string
+	RequestID  string
+}
+
+func (e *APIError) Error() string {
+
+	if e.RequestID != "" {
+		return fmt.Sprintf("people api: %d %s: %s (request id %s)", e.StatusCode, http.StatusText(e.StatusCode), e.Message, e.RequestID)
+	}
+
+	return fmt.Sprintf("people api: %d %s: %s", e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+func (e *APIError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusConflict:
+		return ErrConflict
+	case http.StatusPreconditionFailed:
+		return ErrPreconditionFailed
+	}
+
+	return nil
+}
+
+// newAPIError extracts the message of an error response, the API answers
+// with either {"message": "..."}, a problem with its detail or plain text.
+func newAPIError(statusCode int, requestID string, body []byte) *APIError {
+
+	var payload struct {
+		Message string `json:"message"`
+		Detail  string `json:"detail"`
+	}
+
+	message := strings.TrimSpace(string(body))
+	if err := json.Unmarshal(body, &payload); err == nil && payload.Message != "" {
+		message = payload.Message
+	} else if err == nil && payload.Detail != "" {
+		message = payload.Detail
+	}
+
+	return &APIError{StatusCode: statusCode, Message: message, RequestID: requestID}
+}
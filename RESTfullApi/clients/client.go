@@ -3,48 +3,24 @@ package clients
 import (
 	"context"
 	"errors"
-	"log"
-	"net/http"
-	"os"
-	"os/signal"
-	"sync"
-	"syscall"
+	"log/slog"
 	"time"
 
 	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/timer"
 
-	"github.com/joho/godotenv"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
-func loadEnvFile(logger *log.Logger, filePath string) error {
-
-	if err := godotenv.Load(filePath); err != nil {
-		logger.Println("No .env file found")
-		// return err
-	}
-
-	if os.Getenv("MONGODB_URI_WO_DATABASE") == "" {
-		return errors.New("mongo URI is not set")
-	}
-
-	return nil
-
-}
-
-func ConnectClient(logger *log.Logger, envFilePath string) (*mongo.Client, error) {
+// ConnectClient connects to uri, opts are applied over the options of the
+// connection string.
+func ConnectClient(logger *slog.Logger, uri string, opts ...*options.ClientOptions) (*mongo.Client, error) {
 
 	stop := timer.StartTimer("ConnectClient", logger)
 
 	defer stop()
 
-	if err := loadEnvFile(logger, envFilePath); err != nil {
-		return nil, err
-	}
-
-	uri := os.Getenv("MONGODB_URI_WO_DATABASE")
-
 	if uri == "" {
 		return nil, errors.New("you must set your 'MONGODB_URI' environmental variable. See\n\t https://docs.mongodb.com/drivers/go/current/usage-examples/")
 	}
@@ -52,7 +28,7 @@ func ConnectClient(logger *log.Logger, envFilePath string) (*mongo.Client, error
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 	clientOptions := options.Client().ApplyURI(uri)
-	client, err := mongo.Connect(ctx, clientOptions)
+	client, err := mongo.Connect(ctx, append([]*options.ClientOptions{clientOptions}, opts...)...)
 
 	if err != nil {
 		return nil, err
@@ -61,7 +37,36 @@ func ConnectClient(logger *log.Logger, envFilePath string) (*mongo.Client, error
 	return client, nil
 }
 
-func DisconnectClient(ctx context.Context, client *mongo.Client, logger *log.Logger) error {
+// CommandMonitors returns a monitor calling each of monitors in turn, a client
+// only takes one.
+func CommandMonitors(monitors ...*event.CommandMonitor) *event.CommandMonitor {
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, evt *event.CommandStartedEvent) {
+			for _, monitor := range monitors {
+				if monitor.Started != nil {
+					monitor.Started(ctx, evt)
+				}
+			}
+		},
+		Succeeded: func(ctx context.Context, evt *event.CommandSucceededEvent) {
+			for _, monitor := range monitors {
+				if monitor.Succeeded != nil {
+					monitor.Succeeded(ctx, evt)
+				}
+			}
+		},
+		Failed: func(ctx context.Context, evt *event.CommandFailedEvent) {
+			for _, monitor := range monitors {
+				if monitor.Failed != nil {
+					monitor.Failed(ctx, evt)
+				}
+			}
+		},
+	}
+}
+
+func DisconnectClient(ctx context.Context, client *mongo.Client, logger *slog.Logger) error {
 
 	stop := timer.StartTimer("DisconnectClient", logger)
 
@@ -71,53 +76,8 @@ func DisconnectClient(ctx context.Context, client *mongo.Client, logger *log.Log
 		return err
 	}
 
-	logger.Println("mongo client disconnected")
+	logger.Info("mongo client disconnected")
 
 	return nil
 
 }
-
-func CtrlCHandler(ctx context.Context, client *mongo.Client, logger *log.Logger, server *http.Server) {
-
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
-	errchan := make(chan error, 1)
-	go func() {
-
-		sig := <-c
-		logger.Printf("- Ctrl+C pressed, exiting\n Signal recieved: %v\n", sig)
-		if err := DisconnectClient(ctx, client, logger); err != nil {
-			logger.Printf("Error disconnecting the client: %v\n", err)
-			errchan <- err
-		}
-		if err := server.Shutdown(ctx); err != nil {
-			logger.Printf("Error shutting down the server: %v\n", err)
-			errchan <- err
-		}
-		close(errchan)
-
-	}()
-
-	var wg sync.WaitGroup
-	wg.Add(1)
-	errCheck := false
-	go func() {
-
-		for err := range errchan {
-			logger.Printf("Error: %v\n", err)
-			errCheck = true
-		}
-		wg.Done()
-
-	}()
-
-	go func() {
-		wg.Wait()
-
-		if errCheck {
-			os.Exit(1)
-		}
-		logger.Println("Exiting")
-		os.Exit(0)
-	}()
-}
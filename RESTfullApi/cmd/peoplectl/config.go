@@ -0,0 +1,126 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// config is the peoplectl configuration file:
+	//
+	//	current-profile: local
+	//	profiles:
+	//	  local:
+	//	    server: http://localhost:8080
+	//	  prod:
+	//	    server: https://people.example.com
+	//	    api-key: ...
+	config struct {
+		CurrentProfile string              `yaml:"current-profile"`
+		Profiles       map[string]*profile `yaml:"profiles"`
+	}
+
+	profile struct {
+		Server string `yaml:"server"`
+		APIKey string `yaml:"api-key,omitempty"`
+		Token  string `yaml:"token,omitempty"`
+	}
+)
+
+const (
+	defaultServer  = "http://127.0.0.1:8080"
+	defaultProfile = "default"
+)
+
+func defaultConfigPath() string {
+
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ".peoplectl.yaml"
+	}
+
+	return filepath.Join(dir, "peoplectl", "config.yaml")
+}
+
+// loadConfig reads the configuration file, a missing file is an empty
+// configuration.
+func loadConfig(path string) (*config, error) {
+
+	cfg := &config{Profiles: map[string]*profile{}}
+
+	content, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.Unmarshal(content, cfg); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]*profile{}
+	}
+
+	return cfg, nil
+}
+
+func (c *config) save(path string) error {
+
+	content, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return err
+	}
+
+	// the file holds credentials
+	return os.WriteFile(path, content, 0o600)
+}
+
+// resolve returns the profile to use, name overrides the current profile of
+// the file. Without any profile the default server is used.
+func (c *config) resolve(name string) (*profile, error) {
+
+	if name == "" {
+		name = c.CurrentProfile
+	}
+
+	if name == "" {
+		if p, ok := c.Profiles[defaultProfile]; ok {
+			return p, nil
+		}
+		return &profile{Server: defaultServer}, nil
+	}
+
+	p, ok := c.Profiles[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown profile %q", name)
+	}
+
+	if p.Server == "" {
+		return nil, fmt.Errorf("profile %q has no server", name)
+	}
+
+	return p, nil
+}
+
+func (c *config) profileNames() []string {
+
+	names := make([]string, 0, len(c.Profiles))
+	for name := range c.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
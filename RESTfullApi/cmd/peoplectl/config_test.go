@@ -0,0 +1,40 @@
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveProfile(t *testing.T) {
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+
+	cfg, err := loadConfig(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	p, err := cfg.resolve("")
+	if err != nil || p.Server != defaultServer {
+		t.Fatalf("expected the default server, got %+v, %v", p, err)
+	}
+
+	cfg.Profiles["prod"] = &profile{Server: "https://people.example.com", APIKey: "secret"}
+	cfg.CurrentProfile = "prod"
+	if err := cfg.save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg, err = loadConfig(path); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err = cfg.resolve("")
+	if err != nil || p.Server != "https://people.example.com" || p.APIKey != "secret" {
+		t.Fatalf("expected the prod profile, got %+v, %v", p, err)
+	}
+
+	if _, err := cfg.resolve("staging"); err == nil {
+		t.Fatal("expected an error for an unknown profile")
+	}
+}
@@ -0,0 +1,383 @@
+// Command peoplectl is a command line client for the people API.
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/client"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+
+	"github.com/spf13/cobra"
+)
+
+type options struct {
+	configPath  string
+	profileName string
+	server      string
+	apiKey      string
+	token       string
+	output      string
+}
+
+func main() {
+
+	if err := newRootCommand().Execute(); err != nil {
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+
+	opts := &options{}
+
+	root := &cobra.Command{
+		Use:          "peoplectl",
+		Short:        "Manage the people served by the people API",
+		SilenceUsage: true,
+		// the output format is checked before any call to the API, so that a
+		// person is not created before the command fails
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			return checkFormat("output", opts.output, outputFormats)
+		},
+	}
+
+	flags := root.PersistentFlags()
+	flags.StringVar(&opts.configPath, "config", defaultConfigPath(), "path to the configuration file")
+	flags.StringVarP(&opts.profileName, "profile", "p", "", "configuration profile to use")
+	flags.StringVar(&opts.server, "server", "", "API base url, overrides the profile")
+	flags.StringVar(&opts.apiKey, "api-key", "", "API key, overrides the profile")
+	flags.StringVar(&opts.token, "token", "", "bearer token, overrides the profile")
+	flags.StringVarP(&opts.output, "output", "o", outputTable, "output format: "+strings.Join(outputFormats, ", "))
+
+	_ = root.RegisterFlagCompletionFunc("output", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return outputFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = root.RegisterFlagCompletionFunc("profile", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return completeProfiles(opts)
+	})
+
+	root.AddCommand(
+		newGetCommand(opts),
+		newListCommand(opts),
+		newCreateCommand(opts),
+		newUpdateCommand(opts),
+		newDeleteCommand(opts),
+		newSearchCommand(opts),
+		newImportCommand(opts),
+		newExportCommand(opts),
+		newConfigCommand(opts),
+	)
+
+	return root
+}
+
+// newClient builds a client from the selected profile and the flags
+// overriding it.
+func (o *options) newClient() (*client.Client, error) {
+
+	cfg, err := loadConfig(o.configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// with --server alone, the credentials of the current profile are not
+	// sent to a server they may not belong to
+	p := &profile{}
+	if o.server == "" || o.profileName != "" {
+		if p, err = cfg.resolve(o.profileName); err != nil {
+			return nil, err
+		}
+	}
+
+	server, apiKey, token := p.Server, p.APIKey, p.Token
+	if o.server != "" {
+		server = o.server
+	}
+	if o.apiKey != "" {
+		apiKey = o.apiKey
+	}
+	if o.token != "" {
+		token = o.token
+	}
+
+	return client.New(server, client.WithAPIKey(apiKey), client.WithBearerToken(token), client.WithUserAgent("peoplectl"))
+}
+
+func completeProfiles(opts *options) ([]string, cobra.ShellCompDirective) {
+
+	cfg, err := loadConfig(opts.configPath)
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+
+	return cfg.profileNames(), cobra.ShellCompDirectiveNoFileComp
+}
+
+func newGetCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "get ID",
+		Short: "Show a person",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			p, err := c.Get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			return printPeople(cmd.OutOrStdout(), opts.output, []person{fromPerson(p)}, true)
+		},
+	}
+}
+
+func newListCommand(opts *options) *cobra.Command {
+
+	var pageSize, limit int
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List people",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			var people data.People
+
+			it := c.List(cmd.Context(), client.ListOptions{PageSize: pageSize})
+			for it.Next() {
+				people = append(people, it.Page().People...)
+				if limit > 0 && len(people) >= limit {
+					people = people[:limit]
+					break
+				}
+			}
+			if err := it.Err(); err != nil {
+				return err
+			}
+
+			return printPeople(cmd.OutOrStdout(), opts.output, fromPeople(people), false)
+		},
+	}
+
+	cmd.Flags().IntVar(&pageSize, "page-size", 100, "number of people fetched per request")
+	cmd.Flags().IntVar(&limit, "limit", 0, "maximum number of people to list, 0 lists everybody")
+
+	return cmd
+}
+
+func newCreateCommand(opts *options) *cobra.Command {
+
+	var firstName, lastName string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a person",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			p, err := c.Create(cmd.Context(), data.Person{Firstname: firstName, Lastname: lastName})
+			if err != nil {
+				return err
+			}
+
+			return printPeople(cmd.OutOrStdout(), opts.output, []person{fromPerson(p)}, true)
+		},
+	}
+
+	cmd.Flags().StringVar(&firstName, "first-name", "", "first name of the person")
+	cmd.Flags().StringVar(&lastName, "last-name", "", "last name of the person")
+	_ = cmd.MarkFlagRequired("first-name")
+	_ = cmd.MarkFlagRequired("last-name")
+
+	return cmd
+}
+
+func newUpdateCommand(opts *options) *cobra.Command {
+
+	var firstName, lastName string
+
+	cmd := &cobra.Command{
+		Use:   "update ID",
+		Short: "Update the first and/or last name of a person",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if firstName == "" && lastName == "" {
+				return fmt.Errorf("at least one of --first-name and --last-name is required")
+			}
+
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			if err := c.Update(cmd.Context(), args[0], data.PersonUpdate{Firstname: firstName, Lastname: lastName}); err != nil {
+				return err
+			}
+
+			p, err := c.Get(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			return printPeople(cmd.OutOrStdout(), opts.output, []person{fromPerson(p)}, true)
+		},
+	}
+
+	cmd.Flags().StringVar(&firstName, "first-name", "", "new first name")
+	cmd.Flags().StringVar(&lastName, "last-name", "", "new last name")
+
+	return cmd
+}
+
+func newDeleteCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete ID...",
+		Short: "Delete people",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			for _, id := range args {
+				if err := c.Delete(cmd.Context(), id); err != nil {
+					return fmt.Errorf("deleting %s: %w", id, err)
+				}
+				fmt.Fprintf(cmd.ErrOrStderr(), "deleted %s\n", id)
+			}
+
+			return nil
+		},
+	}
+}
+
+func newSearchCommand(opts *options) *cobra.Command {
+	return &cobra.Command{
+		Use:   "search NAME",
+		Short: "Find the people whose first name contains NAME",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			people, err := c.Search(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+
+			return printPeople(cmd.OutOrStdout(), opts.output, fromPeople(people), false)
+		},
+	}
+}
+
+func newConfigCommand(opts *options) *cobra.Command {
+
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage the server profiles of the configuration file",
+	}
+
+	var server, apiKey, token string
+
+	setProfile := &cobra.Command{
+		Use:   "set-profile NAME",
+		Short: "Create or update a profile",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+
+			p, ok := cfg.Profiles[args[0]]
+			if !ok {
+				p = &profile{}
+				cfg.Profiles[args[0]] = p
+			}
+			if server != "" {
+				p.Server = server
+			}
+			if apiKey != "" {
+				p.APIKey = apiKey
+			}
+			if token != "" {
+				p.Token = token
+			}
+			if p.Server == "" {
+				return fmt.Errorf("profile %q needs a --server", args[0])
+			}
+			if cfg.CurrentProfile == "" {
+				cfg.CurrentProfile = args[0]
+			}
+
+			return cfg.save(opts.configPath)
+		},
+	}
+	setProfile.Flags().StringVar(&server, "server", "", "API base url")
+	setProfile.Flags().StringVar(&apiKey, "api-key", "", "API key")
+	setProfile.Flags().StringVar(&token, "token", "", "bearer token")
+
+	useProfile := &cobra.Command{
+		Use:   "use-profile NAME",
+		Short: "Select the profile used by default",
+		Args:  cobra.ExactArgs(1),
+		ValidArgsFunction: func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+			return completeProfiles(opts)
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				return fmt.Errorf("unknown profile %q", args[0])
+			}
+			cfg.CurrentProfile = args[0]
+
+			return cfg.save(opts.configPath)
+		},
+	}
+
+	profiles := &cobra.Command{
+		Use:   "profiles",
+		Short: "List the profiles, the current one is marked with *",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(opts.configPath)
+			if err != nil {
+				return err
+			}
+
+			for _, name := range cfg.profileNames() {
+				marker := " "
+				if name == cfg.CurrentProfile {
+					marker = "*"
+				}
+				fmt.Fprintf(cmd.OutOrStdout(), "%s %s\t%s\n", marker, name, cfg.Profiles[name].Server)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.AddCommand(setProfile, useProfile, profiles)
+
+	return cmd
+}
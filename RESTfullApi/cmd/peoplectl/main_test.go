@@ -0,0 +1,44 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFormatCheckedBeforeCalls(t *testing.T) {
+
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusCreated)
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	out := filepath.Join(dir, "people.json")
+
+	for _, args := range [][]string{
+		{"create", "-o", "xml", "--first-name", "David"},
+		{"export", "--format", "xml", "--out", out},
+	} {
+		root := newRootCommand()
+		root.SetArgs(append(args, "--server", server.URL, "--config", filepath.Join(dir, "config.yaml")))
+		root.SetOut(io.Discard)
+		root.SetErr(io.Discard)
+
+		if err := root.Execute(); err == nil {
+			t.Errorf("%v: expected an error for the unknown format", args)
+		}
+	}
+
+	if calls != 0 {
+		t.Errorf("expected no call to the API, got %d", calls)
+	}
+	if _, err := os.Stat(out); !os.IsNotExist(err) {
+		t.Errorf("expected no output file, got %v", err)
+	}
+}
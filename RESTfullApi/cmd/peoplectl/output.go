@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"text/tabwriter"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+
+	"gopkg.in/yaml.v3"
+)
+
+// person is how peoplectl prints, imports and exports people.
+type person struct {
+	ID        string `json:"id,omitempty" yaml:"id,omitempty"`
+	FirstName string `json:"firstName" yaml:"firstName"`
+	LastName  string `json:"lastName" yaml:"lastName"`
+}
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+var outputFormats = []string{outputTable, outputJSON, outputYAML}
+
+func fromPerson(p *data.Person) person {
+
+	out := person{FirstName: p.Firstname, LastName: p.Lastname}
+	if !p.ID.IsZero() {
+		out.ID = p.ID.Hex()
+	}
+
+	return out
+}
+
+func fromPeople(people data.People) []person {
+
+	out := make([]person, 0, len(people))
+	for _, p := range people {
+		out = append(out, fromPerson(p))
+	}
+
+	return out
+}
+
+func (p person) toPerson() data.Person {
+	return data.Person{Firstname: p.FirstName, Lastname: p.LastName}
+}
+
+// printPeople writes people in format, single is set when a command returns
+// one person rather than a list.
+func printPeople(w io.Writer, format string, people []person, single bool) error {
+
+	var value interface{} = people
+	if single && len(people) == 1 {
+		value = people[0]
+	}
+
+	switch format {
+	case outputJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(value)
+
+	case outputYAML:
+		encoder := yaml.NewEncoder(w)
+		defer encoder.Close()
+		return encoder.Encode(value)
+
+	case outputTable:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "ID\tFIRST NAME\tLAST NAME")
+		for _, p := range people {
+			fmt.Fprintf(tw, "%s\t%s\t%s\n", p.ID, p.FirstName, p.LastName)
+		}
+		return tw.Flush()
+	}
+
+	return checkFormat("output", format, outputFormats)
+}
+
+// checkFormat returns an error unless format is one of formats, kind names
+// the format in the error.
+func checkFormat(kind, format string, formats []string) error {
+
+	if slices.Contains(formats, format) {
+		return nil
+	}
+
+	return fmt.Errorf("unknown %s format %q, expected one of %v", kind, format, formats)
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/client"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	formatJSON   = "json"
+	formatNDJSON = "ndjson"
+	formatYAML   = "yaml"
+)
+
+var transferFormats = []string{formatNDJSON, formatJSON, formatYAML}
+
+func newImportCommand(opts *options) *cobra.Command {
+
+	var continueOnError bool
+
+	cmd := &cobra.Command{
+		Use:   "import FILE",
+		Short: "Create the people listed in FILE, - reads stdin",
+		Long: "Create the people listed in FILE, either a JSON array or one JSON object per line.\n" +
+			"Ids in the file are ignored, every person is created with a new id.",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			in, err := openInput(args[0])
+			if err != nil {
+				return err
+			}
+			defer in.Close()
+
+			people, err := readPeople(in)
+			if err != nil {
+				return err
+			}
+
+			var created, failed int
+			for i, p := range people {
+				if _, err := c.Create(cmd.Context(), p.toPerson()); err != nil {
+					if !continueOnError {
+						return fmt.Errorf("importing person %d: %w", i+1, err)
+					}
+					fmt.Fprintf(cmd.ErrOrStderr(), "person %d: %v\n", i+1, err)
+					failed++
+					continue
+				}
+				created++
+			}
+
+			fmt.Fprintf(cmd.ErrOrStderr(), "imported %d people, %d failed\n", created, failed)
+
+			if failed > 0 {
+				return fmt.Errorf("%d people could not be imported", failed)
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&continueOnError, "continue-on-error", false, "keep importing after a person is rejected")
+
+	return cmd
+}
+
+func newExportCommand(opts *options) *cobra.Command {
+
+	var format, out string
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "Write every person to a file or stdout",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) (err error) {
+			// the format is checked before the output file is truncated
+			if err := checkFormat("file", format, transferFormats); err != nil {
+				return err
+			}
+
+			c, err := opts.newClient()
+			if err != nil {
+				return err
+			}
+
+			w := cmd.OutOrStdout()
+			if out != "" && out != "-" {
+				var f *os.File
+				if f, err = os.Create(out); err != nil {
+					return err
+				}
+				// the export is only complete once the file is closed
+				defer func() {
+					if closeErr := f.Close(); err == nil {
+						err = closeErr
+					}
+				}()
+				w = f
+			}
+
+			return exportPeople(cmd, c, w, format)
+		},
+	}
+
+	cmd.Flags().StringVar(&format, "format", formatNDJSON, "file format: ndjson, json or yaml")
+	cmd.Flags().StringVar(&out, "out", "-", "output file, - writes to stdout")
+	_ = cmd.RegisterFlagCompletionFunc("format", func(*cobra.Command, []string, string) ([]string, cobra.ShellCompDirective) {
+		return transferFormats, cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+// exportPeople streams ndjson page by page, json and yaml need every person
+// before they can be written.
+func exportPeople(cmd *cobra.Command, c *client.Client, w io.Writer, format string) error {
+
+	var all []person
+
+	buffered := bufio.NewWriter(w)
+	encoder := json.NewEncoder(buffered)
+
+	it := c.List(cmd.Context(), client.ListOptions{})
+	for it.Next() {
+		for _, p := range fromPeople(it.Page().People) {
+			switch format {
+			case formatNDJSON:
+				if err := encoder.Encode(p); err != nil {
+					return err
+				}
+			case formatJSON, formatYAML:
+				all = append(all, p)
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	switch format {
+	case formatJSON:
+		if all == nil {
+			all = []person{}
+		}
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(all); err != nil {
+			return err
+		}
+	case formatYAML:
+		if err := yaml.NewEncoder(buffered).Encode(all); err != nil {
+			return err
+		}
+	}
+
+	return buffered.Flush()
+}
+
+func openInput(path string) (io.ReadCloser, error) {
+
+	if path == "-" {
+		return io.NopCloser(os.Stdin), nil
+	}
+
+	return os.Open(path)
+}
+
+// readPeople accepts a JSON array or newline delimited JSON objects.
+func readPeople(r io.Reader) ([]person, error) {
+
+	buffered := bufio.NewReader(r)
+
+	first, err := peekNonSpace(buffered)
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(buffered)
+	decoder.DisallowUnknownFields()
+
+	if first == '[' {
+		var people []person
+		if err := decoder.Decode(&people); err != nil {
+			return nil, err
+		}
+		return people, nil
+	}
+
+	var people []person
+	for {
+		var p person
+		err := decoder.Decode(&p)
+		if err == io.EOF {
+			return people, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", len(people)+1, err)
+		}
+		people = append(people, p)
+	}
+}
+
+func peekNonSpace(r *bufio.Reader) (byte, error) {
+
+	for {
+		b, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if !bytes.ContainsRune([]byte(" \t\r\n"), rune(b)) {
+			return b, r.UnreadByte()
+		}
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/admin"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/clients"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/config"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/profiler"
+
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// command is a maintenance task run instead of the server, as in
+// `main -database test seed -count 100`. The local commands do not connect to
+// mongo.
+type command struct {
+	usage string
+	run   func(ctx context.Context, collection *mongo.Collection, logger *slog.Logger, args []string) error
+	local func(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error
+}
+
+var commands = map[string]command{
+	"apikey":      {usage: "issue an API key", run: runAPIKey},
+	"seed":        {usage: "insert fake people", run: runSeed},
+	"dump":        {usage: "write the collection as extended JSON lines", run: runDump},
+	"restore":     {usage: "insert the documents of a dump", run: runRestore},
+	"healthcheck": {usage: "probe the readiness of the server, for the Docker HEALTHCHECK", local: runHealthcheck},
+	"pgo":         {usage: "merge the CPU profiles of -profileDir into default.pgo: pgo merge", local: runPGO},
+}
+
+// runCommand runs the command named by args[0] against the configured
+// collection and returns the exit code of the process.
+func runCommand(logger *slog.Logger, cfg config.Config, args []string) int {
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		logger.Error("Unknown command", "command", args[0], "expected", commandNames())
+		return 2
+	}
+
+	if cmd.local != nil {
+		if err := cmd.local(context.Background(), cfg, logger, args[1:]); err != nil {
+			logger.Error("Error running the command", "command", args[0], "error", err)
+			return 1
+		}
+		return 0
+	}
+
+	client, err := clients.ConnectClient(logger, cfg.Mongo.URI)
+	if err != nil {
+		logger.Error("Error while connecting to the mongoDB client", "error", err)
+		return 1
+	}
+
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if err := clients.DisconnectClient(ctx, client, logger); err != nil {
+			logger.Error("Error disconnecting the client", "error", err)
+		}
+	}()
+
+	collection := client.Database(cfg.Mongo.Database).Collection(cfg.Mongo.Collection)
+
+	if err := cmd.run(context.Background(), collection, logger, args[1:]); err != nil {
+		logger.Error("Error running the command", "command", args[0], "database", cfg.Mongo.Database, "collection", cfg.Mongo.Collection, "error", err)
+		return 1
+	}
+
+	return 0
+}
+
+func commandNames() string {
+
+	names := make([]string, 0, len(commands))
+	for name, cmd := range commands {
+		names = append(names, fmt.Sprintf("%s (%s)", name, cmd.usage))
+	}
+	sort.Strings(names)
+
+	return strings.Join(names, ", ")
+}
+
+func runSeed(ctx context.Context, collection *mongo.Collection, logger *slog.Logger, args []string) error {
+
+	flags := flag.NewFlagSet("seed", flag.ContinueOnError)
+	count := flags.Int("count", 100, "number of people to insert")
+	seed := flags.Int64("seed", time.Now().UnixNano(), "seed of the random names")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *count <= 0 {
+		return fmt.Errorf("count must be positive, got %d", *count)
+	}
+
+	inserted, err := admin.Seed(ctx, collection, *count, rand.New(rand.NewSource(*seed)))
+	logger.Info("Inserted people", "count", inserted)
+
+	return err
+}
+
+func runDump(ctx context.Context, collection *mongo.Collection, logger *slog.Logger, args []string) error {
+
+	flags := flag.NewFlagSet("dump", flag.ContinueOnError)
+	out := flags.String("out", "-", "dump file, - writes to stdout")
+	compress := flags.Bool("gzip", false, "gzip the dump, implied by a .gz file name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if *out != "-" {
+		var err error
+		if f, err = os.Create(*out); err != nil {
+			return err
+		}
+		w = f
+	}
+
+	count, err := admin.Dump(ctx, collection, w, *compress || strings.HasSuffix(*out, ".gz"))
+
+	// the dump is only complete once the file is closed
+	if f != nil {
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	logger.Info("Dumped documents", "count", count)
+
+	return nil
+}
+
+func runRestore(ctx context.Context, collection *mongo.Collection, logger *slog.Logger, args []string) error {
+
+	flags := flag.NewFlagSet("restore", flag.ContinueOnError)
+	in := flags.String("in", "-", "dump file, plain or gzipped, - reads stdin")
+	drop := flags.Bool("drop", false, "drop the collection before restoring")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var r io.Reader = os.Stdin
+	if *in != "-" {
+		f, err := os.Open(*in)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	if *drop {
+		if err := collection.Drop(ctx); err != nil {
+			return err
+		}
+	}
+
+	count, err := admin.Restore(ctx, collection, r)
+	logger.Info("Restored documents", "count", count)
+
+	return err
+}
+
+func runAPIKey(ctx context.Context, collection *mongo.Collection, logger *slog.Logger, args []string) error {
+
+	flags := flag.NewFlagSet("apikey", flag.ContinueOnError)
+	name := flags.String("name", "", "name of the key, labels its metrics")
+	scopes := flags.String("scopes", string(auth.ScopeRead), "comma separated scopes: people:read, people:write, admin")
+	ttl := flags.Duration("ttl", 0, "validity of the key, 0 never expires")
+	tenant := flags.String("tenant", "", "tenant the key is bound to, empty keys can act on any tenant")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if *name == "" {
+		return fmt.Errorf("a -name is required")
+	}
+
+	parsed, err := auth.ParseScopes(*scopes)
+	if err != nil {
+		return err
+	}
+
+	keyStore := auth.NewMongoKeyStore(collection.Database().Collection(apiKeysCollection))
+	if err := keyStore.EnsureIndexes(ctx); err != nil {
+		return err
+	}
+
+	_, secret, err := auth.NewAuthenticator(logger, keyStore).Issue(ctx, *name, parsed, *ttl, *tenant)
+	if err != nil {
+		return err
+	}
+
+	// the key is printed alone on stdout so that it can be captured
+	fmt.Println(secret)
+
+	return nil
+}
+
+func runHealthcheck(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+
+	flags := flag.NewFlagSet("healthcheck", flag.ContinueOnError)
+	path := flags.String("path", "/readyz", "probe to request, /healthz only checks that the server answers")
+	timeout := flags.Duration("timeout", 3*time.Second, "time given to the server to answer")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	// the probes are served by the admin server
+	host, port, err := net.SplitHostPort(cfg.Admin.BindAddress)
+	if err != nil {
+		return err
+	}
+
+	// a server listening on every interface is probed on the loopback one
+	if ip := net.ParseIP(host); host == "" || ip != nil && ip.IsUnspecified() {
+		host = "127.0.0.1"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, *timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+net.JoinHostPort(host, port)+*path, nil)
+	if err != nil {
+		return err
+	}
+
+	if cfg.Admin.Token != "" {
+		request.Header.Set(auth.AdminTokenHeader, cfg.Admin.Token)
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	body, _ := io.ReadAll(io.LimitReader(response.Body, 1<<16))
+
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s answered %d: %s", *path, response.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	logger.Debug("The server is healthy", "path", *path)
+
+	return nil
+}
+
+func runPGO(ctx context.Context, cfg config.Config, logger *slog.Logger, args []string) error {
+
+	if len(args) == 0 || args[0] != "merge" {
+		return errors.New("usage: pgo merge [-dir profiles] [-out default.pgo] [-last n]")
+	}
+
+	flags := flag.NewFlagSet("pgo merge", flag.ContinueOnError)
+	dir := flags.String("dir", cfg.Profiling.Dir, "directory of the captured profiles, defaults to -profileDir")
+	out := flags.String("out", "default.pgo", "merged profile, the default.pgo of the main package is used by go build")
+	last := flags.Int("last", 0, "merge only the last n CPU profiles, 0 merges them all")
+	if err := flags.Parse(args[1:]); err != nil {
+		return err
+	}
+
+	if *dir == "" {
+		return errors.New("set the directory of the profiles with -dir or -profileDir")
+	}
+
+	profiles, err := profiler.List(*dir, profiler.CPU)
+	if err != nil {
+		return err
+	}
+
+	if *last > 0 && len(profiles) > *last {
+		profiles = profiles[len(profiles)-*last:]
+	}
+
+	if err := profiler.Merge(*out, profiles...); err != nil {
+		return err
+	}
+
+	logger.Info("Profiles merged", "profiles", len(profiles), "out", *out)
+
+	return nil
+}
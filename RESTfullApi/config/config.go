@@ -0,0 +1,359 @@
+// Package config holds the typed configuration of the API server, loaded from
+// defaults, a YAML or TOML file, the environment and the command line flags.
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/tenant"
+)
+
+type (
+	Config struct {
+		HTTP        HTTP        `yaml:"http" toml:"http"`
+		GRPC        GRPC        `yaml:"grpc" toml:"grpc"`
+		Admin       Admin       `yaml:"admin" toml:"admin"`
+		Mongo       Mongo       `yaml:"mongo" toml:"mongo"`
+		GraphQL     GraphQL     `yaml:"graphql" toml:"graphql"`
+		Auth        Auth        `yaml:"auth" toml:"auth"`
+		Tenant      Tenant      `yaml:"tenant" toml:"tenant"`
+		RateLimit   RateLimit   `yaml:"rateLimit" toml:"rateLimit"`
+		Idempotency Idempotency `yaml:"idempotency" toml:"idempotency"`
+		Cache       Cache       `yaml:"cache" toml:"cache"`
+		Log         Log         `yaml:"log" toml:"log"`
+		Tracing     Tracing     `yaml:"tracing" toml:"tracing"`
+		Profiling   Profiling   `yaml:"profiling" toml:"profiling"`
+	}
+
+	HTTP struct {
+		BindAddress     string        `yaml:"bindAddress" toml:"bindAddress"`
+		ReadTimeout     time.Duration `yaml:"readTimeout" toml:"readTimeout"`
+		WriteTimeout    time.Duration `yaml:"writeTimeout" toml:"writeTimeout"`
+		IdleTimeout     time.Duration `yaml:"idleTimeout" toml:"idleTimeout"`
+		ShutdownTimeout time.Duration `yaml:"shutdownTimeout" toml:"shutdownTimeout"`
+		DrainDelay      time.Duration `yaml:"drainDelay" toml:"drainDelay"`
+		// HealthTimeout bounds each readiness check.
+		HealthTimeout   time.Duration `yaml:"healthTimeout" toml:"healthTimeout"`
+		MaxBodySize     int64         `yaml:"maxBodySize" toml:"maxBodySize"`
+		StrictDecoding  bool          `yaml:"strictDecoding" toml:"strictDecoding"`
+		MetricsEndpoint string        `yaml:"metricsEndpoint" toml:"metricsEndpoint"`
+		// NameEndpoint is the name of the path variable of the people
+		// searched by name.
+		NameEndpoint string `yaml:"nameEndpoint" toml:"nameEndpoint"`
+		// V1Sunset is the HTTP date or RFC 3339 timestamp /v1 is retired
+		// at, empty for none.
+		V1Sunset string `yaml:"v1Sunset" toml:"v1Sunset"`
+		// ReadHandlerTimeout and WriteHandlerTimeout bound the requests to
+		// the people routes reading and writing people, RouteTimeouts those
+		// of the routes named there.
+		ReadHandlerTimeout  time.Duration            `yaml:"readHandlerTimeout" toml:"readHandlerTimeout"`
+		WriteHandlerTimeout time.Duration            `yaml:"writeHandlerTimeout" toml:"writeHandlerTimeout"`
+		RouteTimeouts       map[string]time.Duration `yaml:"routeTimeouts" toml:"routeTimeouts"`
+		// CORSOrigins are the origins browsers may call the API from, * for
+		// any.
+		CORSOrigins []string `yaml:"corsOrigins" toml:"corsOrigins"`
+		// DurationBuckets are the buckets of the request durations, in
+		// seconds, SizeBuckets those of the request and response sizes, in
+		// bytes.
+		DurationBuckets []float64 `yaml:"durationBuckets" toml:"durationBuckets"`
+		SizeBuckets     []float64 `yaml:"sizeBuckets" toml:"sizeBuckets"`
+	}
+
+	GRPC struct {
+		BindAddress string `yaml:"bindAddress" toml:"bindAddress"`
+	}
+
+	// Admin is the listener of the operational endpoints, such as pprof and
+	// the metrics.
+	Admin struct {
+		BindAddress string `yaml:"bindAddress" toml:"bindAddress"`
+		// Token is the token required in X-Admin-Token by the admin endpoints
+		// but the probes and the metrics, empty requires none.
+		Token string `yaml:"token" toml:"token"`
+	}
+
+	Mongo struct {
+		URI        string `yaml:"uri" toml:"uri"`
+		Database   string `yaml:"database" toml:"database"`
+		Collection string `yaml:"collection" toml:"collection"`
+	}
+
+	GraphQL struct {
+		MaxDepth      int `yaml:"maxDepth" toml:"maxDepth"`
+		MaxComplexity int `yaml:"maxComplexity" toml:"maxComplexity"`
+	}
+
+	Auth struct {
+		Enabled     bool   `yaml:"enabled" toml:"enabled"`
+		RBACFile    string `yaml:"rbacFile" toml:"rbacFile"`
+		JWKSSource  string `yaml:"jwksSource" toml:"jwksSource"`
+		Issuer      string `yaml:"issuer" toml:"issuer"`
+		Audience    string `yaml:"audience" toml:"audience"`
+		RolesClaim  string `yaml:"rolesClaim" toml:"rolesClaim"`
+		TenantClaim string `yaml:"tenantClaim" toml:"tenantClaim"`
+	}
+
+	Tenant struct {
+		// Default is the tenant of the requests naming none, empty makes the
+		// tenant required.
+		Default    string `yaml:"default" toml:"default"`
+		Sources    string `yaml:"sources" toml:"sources"`
+		Header     string `yaml:"header" toml:"header"`
+		BaseDomain string `yaml:"baseDomain" toml:"baseDomain"`
+	}
+
+	RateLimit struct {
+		File string `yaml:"file" toml:"file"`
+		// PerMinute is the requests per minute of the routes without a limit
+		// in File, 0 disables it.
+		PerMinute   int    `yaml:"perMinute" toml:"perMinute"`
+		ProxyHeader string `yaml:"proxyHeader" toml:"proxyHeader"`
+	}
+
+	Idempotency struct {
+		TTL time.Duration `yaml:"ttl" toml:"ttl"`
+	}
+
+	Log struct {
+		// Level is debug, info, warn or error.
+		Level string `yaml:"level" toml:"level"`
+		// Format is json or text.
+		Format string `yaml:"format" toml:"format"`
+	}
+
+	Tracing struct {
+		// Exporter is none, otlp, stdout or file.
+		Exporter string `yaml:"exporter" toml:"exporter"`
+		// Endpoint is the URL of the OTLP/HTTP collector, such as
+		// http://localhost:4318/v1/traces, empty uses the OTEL_EXPORTER_OTLP_*
+		// variables.
+		Endpoint    string  `yaml:"endpoint" toml:"endpoint"`
+		File        string  `yaml:"file" toml:"file"`
+		SampleRatio float64 `yaml:"sampleRatio" toml:"sampleRatio"`
+		ServiceName string  `yaml:"serviceName" toml:"serviceName"`
+	}
+
+	Profiling struct {
+		// Dir receives the profiles captured while serving, empty disables
+		// the profiler.
+		Dir      string        `yaml:"dir" toml:"dir"`
+		Interval time.Duration `yaml:"interval" toml:"interval"`
+		// Duration is how long the CPU is profiled every interval.
+		Duration time.Duration `yaml:"duration" toml:"duration"`
+		// Keep is the number of profiles of each kind kept in Dir.
+		Keep int `yaml:"keep" toml:"keep"`
+	}
+
+	Cache struct {
+		// Size is the number of people kept, 0 disables the cache.
+		Size         int           `yaml:"size" toml:"size"`
+		TTL          time.Duration `yaml:"ttl" toml:"ttl"`
+		StaleIfError time.Duration `yaml:"staleIfError" toml:"staleIfError"`
+	}
+)
+
+// Default returns the configuration used for the settings set nowhere else.
+func Default() Config {
+	return Config{
+		HTTP: HTTP{
+			BindAddress:     "127.0.0.1:8080",
+			ReadTimeout:     30 * time.Second,
+			WriteTimeout:    300 * time.Second,
+			IdleTimeout:     120 * time.Second,
+			ShutdownTimeout: 10 * time.Second,
+			HealthTimeout:   2 * time.Second,
+			MaxBodySize:     1 << 20,
+			MetricsEndpoint: "/metrics",
+			NameEndpoint:    "name",
+
+			ReadHandlerTimeout:  10 * time.Second,
+			WriteHandlerTimeout: 5 * time.Second,
+
+			DurationBuckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10},
+			SizeBuckets:     []float64{100, 1000, 10000, 100000, 1000000, 10000000},
+		},
+		GRPC:  GRPC{BindAddress: "127.0.0.1:50051"},
+		Admin: Admin{BindAddress: "127.0.0.1:8081"},
+		Mongo: Mongo{
+			Database:   "thepolyglotdeveloper",
+			Collection: "people",
+		},
+		GraphQL: GraphQL{MaxDepth: 10, MaxComplexity: 1000},
+		Auth: Auth{
+			Enabled:     true,
+			RolesClaim:  "roles",
+			TenantClaim: "tenant",
+		},
+		Tenant: Tenant{
+			Default: "default",
+			Sources: string(tenant.SourceHeader),
+			Header:  tenant.DefaultHeader,
+		},
+		Idempotency: Idempotency{TTL: 24 * time.Hour},
+		Cache:       Cache{TTL: 30 * time.Second},
+		Log:         Log{Level: "info", Format: "json"},
+		Tracing:     Tracing{Exporter: "none", SampleRatio: 1, ServiceName: "people-api"},
+		Profiling:   Profiling{Interval: 10 * time.Minute, Duration: 30 * time.Second, Keep: 24},
+	}
+}
+
+// Validate reports every invalid setting of c.
+func (c Config) Validate() error {
+
+	var errs []error
+
+	check := func(failed bool, format string, args ...interface{}) {
+		if failed {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	checkAddress := func(name, address string) {
+		_, _, err := net.SplitHostPort(address)
+		check(err != nil, "%s %q must be a host:port address", name, address)
+	}
+
+	checkAddress("http.bindAddress", c.HTTP.BindAddress)
+	checkAddress("grpc.bindAddress", c.GRPC.BindAddress)
+	checkAddress("admin.bindAddress", c.Admin.BindAddress)
+	check(c.Admin.BindAddress == c.HTTP.BindAddress, "admin.bindAddress %q must differ from http.bindAddress", c.Admin.BindAddress)
+	check(c.HTTP.ReadTimeout <= 0, "http.readTimeout must be positive")
+	check(c.HTTP.WriteTimeout <= 0, "http.writeTimeout must be positive")
+	check(c.HTTP.IdleTimeout <= 0, "http.idleTimeout must be positive")
+	check(c.HTTP.ShutdownTimeout <= 0, "http.shutdownTimeout must be positive")
+	check(c.HTTP.DrainDelay < 0, "http.drainDelay must not be negative")
+	check(c.HTTP.DrainDelay >= c.HTTP.ShutdownTimeout, "http.drainDelay must be shorter than http.shutdownTimeout")
+	check(c.HTTP.HealthTimeout <= 0, "http.healthTimeout must be positive")
+	check(c.HTTP.MaxBodySize <= 0, "http.maxBodySize must be positive")
+	check(!strings.HasPrefix(c.HTTP.MetricsEndpoint, "/"), "http.metricsEndpoint %q must start with /", c.HTTP.MetricsEndpoint)
+	check(c.HTTP.NameEndpoint == "", "http.nameEndpoint is required")
+	check(c.HTTP.ReadHandlerTimeout <= 0, "http.readHandlerTimeout must be positive")
+	check(c.HTTP.WriteHandlerTimeout <= 0, "http.writeHandlerTimeout must be positive")
+	for route, timeout := range c.HTTP.RouteTimeouts {
+		check(timeout <= 0, "http.routeTimeouts of %q must be positive", route)
+	}
+	check(!increasing(c.HTTP.DurationBuckets), "http.durationBuckets must be increasing")
+	check(!increasing(c.HTTP.SizeBuckets), "http.sizeBuckets must be increasing")
+	_, err := c.HTTP.Sunset()
+	check(err != nil, "http.v1Sunset %q must be an HTTP date or an RFC 3339 timestamp", c.HTTP.V1Sunset)
+
+	check(c.Mongo.URI == "", "mongo.uri is required, set MONGODB_URI_WO_DATABASE or MONGODB_URI_WO_DATABASE_FILE")
+	check(c.Mongo.URI != "" && !strings.HasPrefix(c.Mongo.URI, "mongodb://") && !strings.HasPrefix(c.Mongo.URI, "mongodb+srv://"),
+		"mongo.uri must start with mongodb:// or mongodb+srv://")
+	check(c.Mongo.Database == "", "mongo.database is required")
+	check(c.Mongo.Collection == "", "mongo.collection is required")
+
+	check(c.GraphQL.MaxDepth <= 0, "graphql.maxDepth must be positive")
+	check(c.GraphQL.MaxComplexity <= 0, "graphql.maxComplexity must be positive")
+
+	_, err = tenant.ParseSources(c.Tenant.Sources)
+	check(err != nil, "tenant.sources: %v", err)
+	check(c.Tenant.Header == "", "tenant.header is required")
+
+	check(c.RateLimit.PerMinute < 0, "rateLimit.perMinute can't be negative")
+	check(c.Idempotency.TTL <= 0, "idempotency.ttl must be positive")
+	check(c.Cache.Size < 0, "cache.size can't be negative")
+	check(c.Cache.Size > 0 && c.Cache.TTL <= 0, "cache.ttl must be positive")
+	check(c.Cache.StaleIfError < 0, "cache.staleIfError can't be negative")
+	check(c.Profiling.Dir != "" && c.Profiling.Duration <= 0, "profiling.duration must be positive")
+	check(c.Profiling.Dir != "" && c.Profiling.Interval <= c.Profiling.Duration, "profiling.interval must be longer than profiling.duration")
+	check(c.Profiling.Dir != "" && c.Profiling.Keep <= 0, "profiling.keep must be positive")
+
+	_, err = c.Log.SlogLevel()
+	check(err != nil, "log.level %q must be debug, info, warn or error", c.Log.Level)
+	check(c.Log.Format != "json" && c.Log.Format != "text", "log.format %q must be json or text", c.Log.Format)
+
+	switch c.Tracing.Exporter {
+	case "none", "otlp", "stdout":
+	case "file":
+		check(c.Tracing.File == "", "tracing.file is required by the file exporter")
+	default:
+		check(true, "tracing.exporter %q must be none, otlp, stdout or file", c.Tracing.Exporter)
+	}
+	check(c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1, "tracing.sampleRatio must be between 0 and 1")
+	check(c.Tracing.ServiceName == "", "tracing.serviceName is required")
+
+	return errors.Join(errs...)
+}
+
+// increasing reports whether buckets is not empty and strictly increasing.
+func increasing(buckets []float64) bool {
+
+	for i := 1; i < len(buckets); i++ {
+		if buckets[i] <= buckets[i-1] {
+			return false
+		}
+	}
+
+	return len(buckets) > 0
+}
+
+// Sunset parses V1Sunset, an empty value means no sunset.
+func (h HTTP) Sunset() (time.Time, error) {
+
+	if h.V1Sunset == "" {
+		return time.Time{}, nil
+	}
+
+	if sunset, err := http.ParseTime(h.V1Sunset); err == nil {
+		return sunset, nil
+	}
+
+	return time.Parse(time.RFC3339, h.V1Sunset)
+}
+
+func (l Log) SlogLevel() (slog.Level, error) {
+
+	var level slog.Level
+	err := level.UnmarshalText([]byte(l.Level))
+
+	return level, err
+}
+
+// setDynamic copies the settings that can change while serving from src, the
+// others need a restart.
+func (c *Config) setDynamic(src Config) {
+
+	c.HTTP.ReadHandlerTimeout = src.HTTP.ReadHandlerTimeout
+	c.HTTP.WriteHandlerTimeout = src.HTTP.WriteHandlerTimeout
+	c.HTTP.RouteTimeouts = src.HTTP.RouteTimeouts
+	c.HTTP.CORSOrigins = src.HTTP.CORSOrigins
+	c.RateLimit.File = src.RateLimit.File
+	c.RateLimit.PerMinute = src.RateLimit.PerMinute
+	c.Log.Level = src.Log.Level
+}
+
+// redacted replaces the secrets of a shown configuration.
+const redacted = "REDACTED"
+
+// Redacted returns c without its secrets, to be shown.
+func (c Config) Redacted() Config {
+
+	if u, err := url.Parse(c.Mongo.URI); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+		}
+		c.Mongo.URI = u.String()
+	}
+
+	if c.Admin.Token != "" {
+		c.Admin.Token = redacted
+	}
+
+	return c
+}
+
+// static returns c without its dynamic settings.
+func (c Config) static() Config {
+
+	c.setDynamic(Config{})
+
+	return c
+}
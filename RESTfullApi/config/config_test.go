@@ -0,0 +1,104 @@
+package config
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLoad(t *testing.T) {
+
+	dir := t.TempDir()
+
+	uriFile := filepath.Join(dir, "mongo-uri")
+	if err := os.WriteFile(uriFile, []byte("mongodb://secret:27017\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	configFile := filepath.Join(dir, "config.yaml")
+	err := os.WriteFile(configFile, []byte("http:\n  bindAddress: 0.0.0.0:9000\n  metricsEndpoint: /file-metrics\ncache:\n  size: 100\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"CONFIG_FILE":                  configFile,
+		"MONGODB_URI_WO_DATABASE_FILE": uriFile,
+		"METRICS_ENDPOINT":             "/env-metrics",
+		"CACHE_SIZE":                   "200",
+	}
+
+	cfg, args, err := Load([]string{"-envFilePath", filepath.Join(dir, "missing.env"), "-cacheSize", "300", "-timeout", "5s", "seed", "-count", "3"},
+		func(key string) string { return env[key] }, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Mongo.URI != "mongodb://secret:27017" {
+		t.Errorf("expected the URI of the _FILE, got %q", cfg.Mongo.URI)
+	}
+	if cfg.HTTP.BindAddress != "0.0.0.0:9000" {
+		t.Errorf("expected the bind address of the file, got %q", cfg.HTTP.BindAddress)
+	}
+	if cfg.HTTP.MetricsEndpoint != "/env-metrics" {
+		t.Errorf("expected the environment to override the file, got %q", cfg.HTTP.MetricsEndpoint)
+	}
+	if cfg.Cache.Size != 300 {
+		t.Errorf("expected the flag to override the environment, got %d", cfg.Cache.Size)
+	}
+	if cfg.HTTP.ShutdownTimeout != 5*time.Second {
+		t.Errorf("expected a 5s shutdown timeout, got %v", cfg.HTTP.ShutdownTimeout)
+	}
+	if cfg.GRPC.BindAddress != Default().GRPC.BindAddress {
+		t.Errorf("expected the default gRPC address, got %q", cfg.GRPC.BindAddress)
+	}
+	if strings.Join(args, " ") != "seed -count 3" {
+		t.Errorf("unexpected arguments %v", args)
+	}
+}
+
+func TestLoadTOML(t *testing.T) {
+
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	err := os.WriteFile(configFile, []byte("[mongo]\nuri = \"mongodb://localhost\"\n\n[idempotency]\nttl = \"1h\"\n"), 0o600)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, _, err := Load([]string{"-config", configFile}, func(string) string { return "" }, io.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if cfg.Mongo.URI != "mongodb://localhost" || cfg.Idempotency.TTL != time.Hour {
+		t.Errorf("unexpected configuration %+v", cfg)
+	}
+}
+
+func TestLoadRejectsInvalidSettings(t *testing.T) {
+
+	env := map[string]string{
+		"MONGODB_URI":    "localhost:27017",
+		"BIND_ADDRESS":   "8080",
+		"TENANT_SOURCES": "header,cookie",
+	}
+
+	_, _, err := Load(nil, func(key string) string { return env[key] }, io.Discard)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	for _, setting := range []string{"mongo.uri", "http.bindAddress", "tenant.sources"} {
+		if !strings.Contains(err.Error(), setting) {
+			t.Errorf("expected %s to be reported in %v", setting, err)
+		}
+	}
+
+	env = map[string]string{"MONGODB_URI": "mongodb://localhost"}
+	if _, _, err := Load([]string{"-cacheSize", "many"}, func(key string) string { return env[key] }, io.Discard); err == nil || !strings.Contains(err.Error(), "-cacheSize") {
+		t.Errorf("expected the flag to be reported, got %v", err)
+	}
+}
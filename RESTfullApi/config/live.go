@@ -0,0 +1,182 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/observability"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Live holds the configuration of the running server. Reload loads it again
+// and swaps its dynamic settings, the others keep their value until the
+// server restarts.
+type Live struct {
+	logger *slog.Logger
+	load   func() (Config, error)
+
+	mu         sync.Mutex
+	current    atomic.Pointer[Config]
+	generation atomic.Uint64
+	hooks      []func(Config)
+}
+
+const (
+	reloadSucceeded = "success"
+	reloadFailed    = "failure"
+
+	errorReloading        = "Error while reloading the configuration, keeping the current generation"
+	reloaded              = "Configuration reloaded"
+	restartRequired       = "The reloaded configuration changes settings that need a restart, they are ignored"
+	errorWrittingResponse = "Error while writing the reload response"
+	errorEncodingConfig   = "Error while encoding the configuration"
+	errorWrittingConfig   = "Error while writing the configuration response"
+)
+
+// NewLive returns the first generation of the configuration, load is called
+// by Reload and must return a validated configuration.
+func NewLive(logger *slog.Logger, config Config, load func() (Config, error)) *Live {
+
+	live := &Live{logger: logger, load: load}
+	live.current.Store(&config)
+	live.generation.Store(1)
+	observability.ConfigGeneration.Set(1)
+
+	return live
+}
+
+func (l *Live) Current() Config {
+	return *l.current.Load()
+}
+
+func (l *Live) Generation() uint64 {
+	return l.generation.Load()
+}
+
+// OnReload calls apply with the configuration of every successful reload,
+// apply must not fail: the configuration was validated by load.
+func (l *Live) OnReload(apply func(Config)) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, apply)
+}
+
+// Reload loads the configuration and swaps its dynamic settings, an invalid
+// configuration is rejected and the current one kept. ctx is the one of the
+// records logged.
+func (l *Live) Reload(ctx context.Context) (Config, error) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	loaded, err := l.load()
+	if err != nil {
+		observability.ConfigReloads.WithLabelValues(reloadFailed).Inc()
+		l.logger.ErrorContext(ctx, errorReloading, "generation", l.Generation(), "error", err)
+		return Config{}, err
+	}
+
+	next := l.Current()
+	if !reflect.DeepEqual(next.static(), loaded.static()) {
+		l.logger.WarnContext(ctx, restartRequired)
+	}
+	next.setDynamic(loaded)
+
+	l.current.Store(&next)
+	for _, apply := range l.hooks {
+		apply(next)
+	}
+
+	generation := l.generation.Add(1)
+	observability.ConfigGeneration.Set(float64(generation))
+	observability.ConfigReloads.WithLabelValues(reloadSucceeded).Inc()
+	l.logger.InfoContext(ctx, reloaded, "generation", generation)
+
+	return next, nil
+}
+
+// WatchSignals reloads the configuration on every SIGHUP until ctx is done.
+func (l *Live) WatchSignals(ctx context.Context) {
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-signals:
+				// failures are logged and counted
+				_, _ = l.Reload(ctx)
+			}
+		}
+	}()
+}
+
+// ReloadEndpoint reloads the configuration, it answers 422 with the reason
+// when the configuration is rejected.
+func (l *Live) ReloadEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set("content-type", "application/json")
+
+	var body interface{}
+	if _, err := l.Reload(request.Context()); err != nil {
+		response.WriteHeader(http.StatusUnprocessableEntity)
+		body = struct {
+			Message    string `json:"message"`
+			Generation uint64 `json:"generation"`
+		}{err.Error(), l.Generation()}
+	} else {
+		body = struct {
+			Generation uint64 `json:"generation"`
+		}{l.Generation()}
+	}
+
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		l.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+	}
+}
+
+// ConfigEndpoint answers the configuration in use without its secrets, with
+// the names of the configuration file, and its generation.
+func (l *Live) ConfigEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	generation := l.Generation()
+
+	// the YAML encoding names the settings as the file does and writes the
+	// durations as such
+	encoded, err := yaml.Marshal(l.Current().Redacted())
+	var config map[string]interface{}
+	if err == nil {
+		err = yaml.Unmarshal(encoded, &config)
+	}
+	if err != nil {
+		l.logger.ErrorContext(request.Context(), errorEncodingConfig, "error", err)
+		http.Error(response, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+
+	response.Header().Set("content-type", "application/json")
+
+	body := struct {
+		Generation uint64                 `json:"generation"`
+		Config     map[string]interface{} `json:"config"`
+	}{generation, config}
+
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		l.logger.ErrorContext(request.Context(), errorWrittingConfig, "error", err)
+	}
+}
@@ -0,0 +1,364 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"github.com/joho/godotenv"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// setting binds a field of Config to a flag and to environment variables,
+	// the first variable set wins. The variables of a secret setting can
+	// also name a file holding the value with the _FILE suffix.
+	setting struct {
+		flag   string
+		env    []string
+		secret bool
+		usage  string
+		field  func(c *Config) interface{}
+	}
+
+	// flagValue keeps the value of a flag until the file and the environment
+	// were applied, flags take precedence over both.
+	flagValue struct {
+		def    string
+		isBool bool
+		raw    *string
+	}
+)
+
+const fileSuffix = "_FILE"
+
+var settings = []setting{
+	{"bindAddress", []string{"BIND_ADDRESS"}, false, "address of the HTTP server", func(c *Config) interface{} { return &c.HTTP.BindAddress }},
+	{"readTimeout", []string{"HTTP_READ_TIMEOUT"}, false, "max time to read a request from the client", func(c *Config) interface{} { return &c.HTTP.ReadTimeout }},
+	{"writeTimeout", []string{"HTTP_WRITE_TIMEOUT"}, false, "max time to write a response to the client", func(c *Config) interface{} { return &c.HTTP.WriteTimeout }},
+	{"idleTimeout", []string{"HTTP_IDLE_TIMEOUT"}, false, "max time for connections using TCP Keep-Alive", func(c *Config) interface{} { return &c.HTTP.IdleTimeout }},
+	{"timeout", []string{"SHUTDOWN_TIMEOUT"}, false, "overall deadline of the shutdown", func(c *Config) interface{} { return &c.HTTP.ShutdownTimeout }},
+	{"drainDelay", []string{"DRAIN_DELAY"}, false, "time the servers keep serving once readiness fails on shutdown", func(c *Config) interface{} { return &c.HTTP.DrainDelay }},
+	{"healthTimeout", []string{"HEALTH_TIMEOUT"}, false, "time given to each readiness check", func(c *Config) interface{} { return &c.HTTP.HealthTimeout }},
+	{"maxBodySize", []string{"MAX_BODY_SIZE"}, false, "maximum size in bytes of a request body", func(c *Config) interface{} { return &c.HTTP.MaxBodySize }},
+	{"strictDecoding", []string{"STRICT_DECODING"}, false, "reject request bodies with unknown fields", func(c *Config) interface{} { return &c.HTTP.StrictDecoding }},
+	{"metricsEndpoint", []string{"METRICS_ENDPOINT"}, false, "path of the prometheus metrics", func(c *Config) interface{} { return &c.HTTP.MetricsEndpoint }},
+	{"nameEndpoint", []string{"NAME_ENDPOINT"}, false, "path variable of the people searched by name", func(c *Config) interface{} { return &c.HTTP.NameEndpoint }},
+	{"v1Sunset", []string{"V1_SUNSET"}, false, "HTTP date or RFC 3339 timestamp /v1 is retired at", func(c *Config) interface{} { return &c.HTTP.V1Sunset }},
+	{"readHandlerTimeout", []string{"READ_HANDLER_TIMEOUT"}, false, "timeout of the routes reading people, reloadable", func(c *Config) interface{} { return &c.HTTP.ReadHandlerTimeout }},
+	{"writeHandlerTimeout", []string{"WRITE_HANDLER_TIMEOUT"}, false, "timeout of the routes writing people, reloadable", func(c *Config) interface{} { return &c.HTTP.WriteHandlerTimeout }},
+	{"routeTimeouts", []string{"ROUTE_TIMEOUTS"}, false, "comma separated route=timeout overriding the read and write timeouts, such as listPeople=30s, reloadable", func(c *Config) interface{} { return &c.HTTP.RouteTimeouts }},
+	{"corsOrigins", []string{"CORS_ORIGINS"}, false, "comma separated origins allowed to call the API from a browser, * for any, reloadable", func(c *Config) interface{} { return &c.HTTP.CORSOrigins }},
+	{"httpDurationBuckets", []string{"HTTP_DURATION_BUCKETS"}, false, "comma separated buckets of the request durations, in seconds", func(c *Config) interface{} { return &c.HTTP.DurationBuckets }},
+	{"httpSizeBuckets", []string{"HTTP_SIZE_BUCKETS"}, false, "comma separated buckets of the request and response sizes, in bytes", func(c *Config) interface{} { return &c.HTTP.SizeBuckets }},
+	{"logLevel", []string{"LOG_LEVEL"}, false, "debug, info, warn or error, reloadable", func(c *Config) interface{} { return &c.Log.Level }},
+	{"logFormat", []string{"LOG_FORMAT"}, false, "json or text", func(c *Config) interface{} { return &c.Log.Format }},
+	{"traceExporter", []string{"TRACE_EXPORTER"}, false, "where the traces are sent: none, otlp, stdout or file", func(c *Config) interface{} { return &c.Tracing.Exporter }},
+	{"traceEndpoint", []string{"TRACE_ENDPOINT"}, false, "URL of the OTLP/HTTP collector, empty uses the OTEL_EXPORTER_OTLP_* variables", func(c *Config) interface{} { return &c.Tracing.Endpoint }},
+	{"traceFile", []string{"TRACE_FILE"}, false, "file the file exporter appends the spans to", func(c *Config) interface{} { return &c.Tracing.File }},
+	{"traceSampleRatio", []string{"TRACE_SAMPLE_RATIO"}, false, "ratio of the traces started by this service that are sampled", func(c *Config) interface{} { return &c.Tracing.SampleRatio }},
+	{"serviceName", []string{"OTEL_SERVICE_NAME"}, false, "name of the service in the traces", func(c *Config) interface{} { return &c.Tracing.ServiceName }},
+	{"grpcBindAddress", []string{"GRPC_BIND_ADDRESS"}, false, "address of the gRPC server", func(c *Config) interface{} { return &c.GRPC.BindAddress }},
+	{"adminBindAddress", []string{"ADMIN_BIND_ADDRESS"}, false, "address of the admin server, serving pprof, the metrics, the probes and the admin endpoints", func(c *Config) interface{} { return &c.Admin.BindAddress }},
+	{"", []string{"MONGODB_URI_WO_DATABASE", "MONGODB_URI"}, true, "mongo connection string", func(c *Config) interface{} { return &c.Mongo.URI }},
+	{"", []string{"ADMIN_TOKEN"}, true, "token of the admin endpoints, sent in X-Admin-Token", func(c *Config) interface{} { return &c.Admin.Token }},
+	{"database", []string{"MONGODB_DATABASE"}, false, "mongo database holding the people", func(c *Config) interface{} { return &c.Mongo.Database }},
+	{"collection", []string{"MONGODB_COLLECTION"}, false, "mongo collection holding the people", func(c *Config) interface{} { return &c.Mongo.Collection }},
+	{"graphqlMaxDepth", []string{"GRAPHQL_MAX_DEPTH"}, false, "maximum depth of a graphql query", func(c *Config) interface{} { return &c.GraphQL.MaxDepth }},
+	{"graphqlMaxComplexity", []string{"GRAPHQL_MAX_COMPLEXITY"}, false, "maximum complexity of a graphql query", func(c *Config) interface{} { return &c.GraphQL.MaxComplexity }},
+	{"apiKeyAuth", []string{"API_KEY_AUTH"}, false, "require an API key or a bearer token on every route but the metrics", func(c *Config) interface{} { return &c.Auth.Enabled }},
+	{"rbacFile", []string{"RBAC_FILE"}, false, "YAML file mapping token roles to scopes and routes to the scope they require", func(c *Config) interface{} { return &c.Auth.RBACFile }},
+	{"jwksSource", []string{"JWKS_SOURCE"}, false, "file or URL of the JSON Web Key Set of the bearer tokens, empty accepts API keys only", func(c *Config) interface{} { return &c.Auth.JWKSSource }},
+	{"jwtIssuer", []string{"JWT_ISSUER"}, false, "required issuer of the bearer tokens", func(c *Config) interface{} { return &c.Auth.Issuer }},
+	{"jwtAudience", []string{"JWT_AUDIENCE"}, false, "required audience of the bearer tokens", func(c *Config) interface{} { return &c.Auth.Audience }},
+	{"jwtRolesClaim", []string{"JWT_ROLES_CLAIM"}, false, "claim holding the roles of a bearer token", func(c *Config) interface{} { return &c.Auth.RolesClaim }},
+	{"jwtTenantClaim", []string{"JWT_TENANT_CLAIM"}, false, "claim holding the tenant of a bearer token", func(c *Config) interface{} { return &c.Auth.TenantClaim }},
+	{"defaultTenant", []string{"DEFAULT_TENANT"}, false, "tenant of the requests naming none, served from -database and -collection, empty requires a tenant", func(c *Config) interface{} { return &c.Tenant.Default }},
+	{"tenantSources", []string{"TENANT_SOURCES"}, false, "comma separated sources of the tenant of a request: header, subdomain, claim", func(c *Config) interface{} { return &c.Tenant.Sources }},
+	{"tenantHeader", []string{"TENANT_HEADER"}, false, "header naming the tenant of a request", func(c *Config) interface{} { return &c.Tenant.Header }},
+	{"tenantBaseDomain", []string{"TENANT_BASE_DOMAIN"}, false, "domain whose subdomains name tenants", func(c *Config) interface{} { return &c.Tenant.BaseDomain }},
+	{"rateLimitFile", []string{"RATE_LIMIT_FILE"}, false, "YAML file with the default rate limit and the limits of named routes, reloadable", func(c *Config) interface{} { return &c.RateLimit.File }},
+	{"rateLimit", []string{"RATE_LIMIT"}, false, "requests per minute allowed to each client of a route without a limit in -rateLimitFile, 0 disables it, reloadable", func(c *Config) interface{} { return &c.RateLimit.PerMinute }},
+	{"rateLimitProxyHeader", []string{"RATE_LIMIT_PROXY_HEADER"}, false, "header set by a trusted proxy with the client address, such as X-Forwarded-For", func(c *Config) interface{} { return &c.RateLimit.ProxyHeader }},
+	{"idempotencyTTL", []string{"IDEMPOTENCY_TTL"}, false, "how long the response of a POST with an Idempotency-Key is replayed", func(c *Config) interface{} { return &c.Idempotency.TTL }},
+	{"cacheSize", []string{"CACHE_SIZE"}, false, "number of people read by id kept in memory, 0 disables the cache", func(c *Config) interface{} { return &c.Cache.Size }},
+	{"cacheTTL", []string{"CACHE_TTL"}, false, "how long a cached person is served", func(c *Config) interface{} { return &c.Cache.TTL }},
+	{"cacheStaleIfError", []string{"CACHE_STALE_IF_ERROR"}, false, "how long after expiring a cached person is served when mongo fails", func(c *Config) interface{} { return &c.Cache.StaleIfError }},
+	{"profileDir", []string{"PROFILE_DIR"}, false, "directory receiving CPU and heap profiles captured while serving, for pgo merge, empty disables the profiler", func(c *Config) interface{} { return &c.Profiling.Dir }},
+	{"profileInterval", []string{"PROFILE_INTERVAL"}, false, "time between two captures of the profiles", func(c *Config) interface{} { return &c.Profiling.Interval }},
+	{"profileDuration", []string{"PROFILE_DURATION"}, false, "how long the CPU is profiled at every capture", func(c *Config) interface{} { return &c.Profiling.Duration }},
+	{"profileKeep", []string{"PROFILE_KEEP"}, false, "number of profiles of each kind kept in the directory", func(c *Config) interface{} { return &c.Profiling.Keep }},
+}
+
+// Load builds the configuration from args, the command line without the
+// program name, and returns it with the arguments left after the flags. The
+// flags take precedence over the environment, read with getenv and then from
+// the -envFilePath file, which takes precedence over the -config file, which
+// takes precedence over the defaults. The configuration is validated.
+func Load(args []string, getenv func(string) string, output io.Writer) (Config, []string, error) {
+
+	defaults := Default()
+
+	fs := flag.NewFlagSet("main", flag.ContinueOnError)
+	fs.SetOutput(output)
+
+	envFilePath := fs.String("envFilePath", "../.env", "path to .env file")
+	configFile := fs.String("config", "", "YAML or TOML configuration file (CONFIG_FILE)")
+
+	raw := make([]*string, len(settings))
+	for i, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+		raw[i] = new(string)
+		target := s.field(&defaults)
+		_, isBool := target.(*bool)
+		fs.Var(&flagValue{def: format(target), isBool: isBool, raw: raw[i]}, s.flag, fmt.Sprintf("%s (%s)", s.usage, strings.Join(s.env, ", ")))
+	}
+
+	if err := fs.Parse(args); err != nil {
+		return Config{}, nil, err
+	}
+
+	setFlags := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	dotenv, err := godotenv.Read(*envFilePath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return Config{}, nil, fmt.Errorf("reading %s: %w", *envFilePath, err)
+	}
+
+	lookup := func(key string) string {
+		if value := getenv(key); value != "" {
+			return value
+		}
+		return dotenv[key]
+	}
+
+	config := Default()
+
+	if !setFlags["config"] {
+		*configFile = lookup("CONFIG_FILE")
+	}
+	if *configFile != "" {
+		if err := decodeFile(*configFile, &config); err != nil {
+			return Config{}, nil, err
+		}
+	}
+
+	for _, s := range settings {
+		value, source, err := s.lookupEnv(lookup)
+		if err != nil {
+			return Config{}, nil, err
+		}
+		if source == "" {
+			continue
+		}
+		if err := parse(s.field(&config), value); err != nil {
+			return Config{}, nil, fmt.Errorf("%s: %w", source, err)
+		}
+	}
+
+	for i, s := range settings {
+		if s.flag == "" || !setFlags[s.flag] {
+			continue
+		}
+		if err := parse(s.field(&config), *raw[i]); err != nil {
+			return Config{}, nil, fmt.Errorf("flag -%s: %w", s.flag, err)
+		}
+	}
+
+	if err := config.Validate(); err != nil {
+		return Config{}, nil, fmt.Errorf("invalid configuration:\n%w", err)
+	}
+
+	return config, fs.Args(), nil
+}
+
+// lookupEnv returns the value of the first variable of s set and its name,
+// an empty name when none is set.
+func (s setting) lookupEnv(lookup func(string) string) (string, string, error) {
+
+	for _, name := range s.env {
+		if value := lookup(name); value != "" {
+			return value, name, nil
+		}
+
+		if !s.secret {
+			continue
+		}
+
+		path := lookup(name + fileSuffix)
+		if path == "" {
+			continue
+		}
+
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return "", "", fmt.Errorf("%s%s: %w", name, fileSuffix, err)
+		}
+
+		return strings.TrimSpace(string(content)), name + fileSuffix, nil
+	}
+
+	return "", "", nil
+}
+
+// decodeFile decodes the TOML file at path when its extension is .toml, the
+// YAML one otherwise. Unknown keys are rejected to catch typos.
+func decodeFile(path string, config *Config) error {
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	if filepath.Ext(path) == ".toml" {
+		metadata, err := toml.Decode(string(content), config)
+		if err != nil {
+			return fmt.Errorf("parsing %s: %w", path, err)
+		}
+		if undecoded := metadata.Undecoded(); len(undecoded) > 0 {
+			return fmt.Errorf("parsing %s: unknown keys %v", path, undecoded)
+		}
+		return nil
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+
+	if err := decoder.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func parse(target interface{}, value string) error {
+
+	var err error
+
+	switch target := target.(type) {
+	case *string:
+		*target = value
+	case *int:
+		*target, err = strconv.Atoi(value)
+	case *int64:
+		*target, err = strconv.ParseInt(value, 10, 64)
+	case *float64:
+		*target, err = strconv.ParseFloat(value, 64)
+	case *bool:
+		*target, err = strconv.ParseBool(value)
+	case *time.Duration:
+		*target, err = time.ParseDuration(value)
+	case *[]string:
+		*target = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*target = append(*target, item)
+			}
+		}
+	case *[]float64:
+		*target = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			bucket, parseErr := strconv.ParseFloat(item, 64)
+			if parseErr != nil {
+				err = parseErr
+				break
+			}
+			*target = append(*target, bucket)
+		}
+	case *map[string]time.Duration:
+		*target = make(map[string]time.Duration)
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, duration, found := strings.Cut(item, "=")
+			if !found {
+				err = fmt.Errorf("%q is not a key=duration pair", item)
+				break
+			}
+			parsed, parseErr := time.ParseDuration(strings.TrimSpace(duration))
+			if parseErr != nil {
+				err = parseErr
+				break
+			}
+			(*target)[strings.TrimSpace(key)] = parsed
+		}
+	default:
+		err = fmt.Errorf("unsupported setting type %T", target)
+	}
+
+	if err != nil {
+		return fmt.Errorf("invalid value %q: %w", value, err)
+	}
+
+	return nil
+}
+
+func format(target interface{}) string {
+
+	switch target := target.(type) {
+	case *string:
+		return *target
+	case *int:
+		return strconv.Itoa(*target)
+	case *int64:
+		return strconv.FormatInt(*target, 10)
+	case *float64:
+		return strconv.FormatFloat(*target, 'g', -1, 64)
+	case *bool:
+		return strconv.FormatBool(*target)
+	case *time.Duration:
+		return target.String()
+	case *[]string:
+		return strings.Join(*target, ",")
+	case *[]float64:
+		items := make([]string, len(*target))
+		for i, item := range *target {
+			items[i] = strconv.FormatFloat(item, 'g', -1, 64)
+		}
+		return strings.Join(items, ",")
+	case *map[string]time.Duration:
+		items := make([]string, 0, len(*target))
+		for key, duration := range *target {
+			items = append(items, key+"="+duration.String())
+		}
+		sort.Strings(items)
+		return strings.Join(items, ",")
+	}
+
+	return ""
+}
+
+func (f *flagValue) String() string {
+
+	if f == nil {
+		return ""
+	}
+
+	return f.def
+}
+
+func (f *flagValue) Set(value string) error {
+	*f.raw = value
+	return nil
+}
+
+// IsBoolFlag lets boolean flags be set without a value, as in -strictDecoding.
+func (f *flagValue) IsBoolFlag() bool {
+	return f.isBool
+}
@@ -0,0 +1,37 @@
+package data
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+// ErrTrailingData is returned when a JSON value is followed by anything but
+// white space.
+var ErrTrailingData = errors.New("unexpected data after the JSON value")
+
+// DecodeJSON decodes the single JSON value read from r into v, strict rejects
+// the fields v does not know.
+func DecodeJSON(r io.Reader, v interface{}, strict bool) error {
+
+	decoder := json.NewDecoder(r)
+	if strict {
+		decoder.DisallowUnknownFields()
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return err
+	}
+
+	_, err := decoder.Token()
+	if err == io.EOF {
+		return nil
+	}
+
+	var syntaxErr *json.SyntaxError
+	if err == nil || errors.As(err, &syntaxErr) {
+		return ErrTrailingData
+	}
+
+	return err
+}
@@ -33,7 +33,7 @@ func (p *Person) ToJSON(w io.Writer) error {
 
 func (p *Person) FromJSON(r io.Reader) error {
 
-	return json.NewDecoder(r).Decode(&p)
+	return DecodeJSON(r, p, false)
 }
 
 func (p *PersonUpdate) ToJSON(w io.Writer) error {
@@ -43,7 +43,7 @@ func (p *PersonUpdate) ToJSON(w io.Writer) error {
 
 func (p *PersonUpdate) FromJSON(r io.Reader) error {
 
-	return json.NewDecoder(r).Decode(&p)
+	return DecodeJSON(r, p, false)
 }
 
 func (p *People) ToJSON(w io.Writer) error {
@@ -57,7 +57,7 @@ func (p *People) ToJSON(w io.Writer) error {
 
 func (p *People) FromJSON(r io.Reader) error {
 
-	return json.NewDecoder(r).Decode(&p)
+	return DecodeJSON(r, p, false)
 }
 
 func (p *Person) Validate() error {
@@ -1,6 +1,11 @@
 package data
 
-import "testing"
+import (
+	"strings"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
 func TestCheckValidationPerson(t *testing.T) {
 
@@ -31,3 +36,56 @@ func TestCheckValidationPersonUpdate(t *testing.T) {
 	}
 
 }
+
+func TestPersonV2RoundTrip(t *testing.T) {
+
+	person := &Person{
+		ID:        primitive.NewObjectID(),
+		Firstname: "David",
+		Lastname:  "Hernandez",
+	}
+
+	dto := NewPersonV2(person)
+
+	if dto.ID != person.ID.Hex() || dto.FirstName != person.Firstname || dto.LastName != person.Lastname {
+		t.Fatalf("unexpected v2 person: %+v", dto)
+	}
+
+	got, err := dto.ToPerson()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got != *person {
+		t.Fatalf("expected %+v, got %+v", *person, got)
+	}
+
+}
+
+func TestDecodeJSON(t *testing.T) {
+
+	tests := []struct {
+		name   string
+		body   string
+		strict bool
+		ok     bool
+	}{
+		{"valid", `{"firstName": "David"}` + "\n", true, true},
+		{"unknown field", `{"firstName": "David", "age": 3}`, false, true},
+		{"strict unknown field", `{"firstName": "David", "age": 3}`, true, false},
+		{"trailing value", `{"firstName": "David"} {}`, false, false},
+		{"trailing garbage", `{"firstName": "David"}x`, false, false},
+		{"malformed", `{"firstName": `, false, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var person PersonV2
+			err := DecodeJSON(strings.NewReader(test.body), &person, test.strict)
+			if (err == nil) != test.ok {
+				t.Fatalf("expected ok %v, got %v", test.ok, err)
+			}
+		})
+	}
+}
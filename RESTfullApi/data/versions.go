@@ -0,0 +1,186 @@
+package data
+
+import (
+	"encoding/json"
+	"io"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// The types below are the wire representations of the shared model for each
+// API version. Person can change freely as long as the mappings are kept.
+type (
+	// PersonV1 is the /v1 representation of a Person, frozen to the original shape.
+	PersonV1 struct {
+		ID        primitive.ObjectID `json:"_id,omitempty"`
+		Firstname string             `json:"firstname,omitempty"`
+		Lastname  string             `json:"lastname,omitempty"`
+	}
+
+	PersonUpdateV1 struct {
+		Firstname string `json:"firstname,omitempty"`
+		Lastname  string `json:"lastname,omitempty"`
+	}
+
+	PeopleV1 []*PersonV1
+
+	// PersonV2 is the /v2 representation of a Person.
+	PersonV2 struct {
+		ID        string `json:"id,omitempty"`
+		FirstName string `json:"firstName"`
+		LastName  string `json:"lastName"`
+	}
+
+	PersonUpdateV2 struct {
+		FirstName string `json:"firstName,omitempty"`
+		LastName  string `json:"lastName,omitempty"`
+	}
+
+	// PeopleV2 wraps the list of people in an envelope so it can grow without
+	// breaking clients. Next is the cursor of the following page, if any.
+	PeopleV2 struct {
+		People []*PersonV2 `json:"people"`
+		Count  int         `json:"count"`
+		Next   string      `json:"next,omitempty"`
+	}
+)
+
+func NewPersonV1(p *Person) *PersonV1 {
+
+	return &PersonV1{
+		ID:        p.ID,
+		Firstname: p.Firstname,
+		Lastname:  p.Lastname,
+	}
+}
+
+func (p *PersonV1) ToPerson() Person {
+
+	return Person{
+		ID:        p.ID,
+		Firstname: p.Firstname,
+		Lastname:  p.Lastname,
+	}
+}
+
+func (p *PersonV1) ToJSON(w io.Writer) error {
+
+	return json.NewEncoder(w).Encode(p)
+}
+
+func (p *PersonV1) FromJSON(r io.Reader) error {
+
+	return DecodeJSON(r, p, false)
+}
+
+func (p *PersonUpdateV1) ToPersonUpdate() PersonUpdate {
+
+	return PersonUpdate{
+		Firstname: p.Firstname,
+		Lastname:  p.Lastname,
+	}
+}
+
+func (p *PersonUpdateV1) FromJSON(r io.Reader) error {
+
+	return DecodeJSON(r, p, false)
+}
+
+func NewPeopleV1(people People) PeopleV1 {
+
+	result := make(PeopleV1, 0, len(people))
+	for _, p := range people {
+		result = append(result, NewPersonV1(p))
+	}
+
+	return result
+}
+
+func (p PeopleV1) ToJSON(w io.Writer) error {
+
+	if len(p) == 0 {
+		return ErrNotFound
+	}
+
+	return json.NewEncoder(w).Encode(p)
+}
+
+func NewPersonV2(p *Person) *PersonV2 {
+
+	person := &PersonV2{
+		FirstName: p.Firstname,
+		LastName:  p.Lastname,
+	}
+
+	if !p.ID.IsZero() {
+		person.ID = p.ID.Hex()
+	}
+
+	return person
+}
+
+func (p *PersonV2) ToPerson() (Person, error) {
+
+	person := Person{
+		Firstname: p.FirstName,
+		Lastname:  p.LastName,
+	}
+
+	if p.ID == "" {
+		return person, nil
+	}
+
+	id, err := primitive.ObjectIDFromHex(p.ID)
+	if err != nil {
+		return Person{}, err
+	}
+	person.ID = id
+
+	return person, nil
+}
+
+func (p *PersonV2) ToJSON(w io.Writer) error {
+
+	return json.NewEncoder(w).Encode(p)
+}
+
+func (p *PersonV2) FromJSON(r io.Reader) error {
+
+	return DecodeJSON(r, p, false)
+}
+
+func (p *PersonUpdateV2) ToPersonUpdate() PersonUpdate {
+
+	return PersonUpdate{
+		Firstname: p.FirstName,
+		Lastname:  p.LastName,
+	}
+}
+
+func (p *PersonUpdateV2) FromJSON(r io.Reader) error {
+
+	return DecodeJSON(r, p, false)
+}
+
+func NewPeopleV2(people People) *PeopleV2 {
+
+	result := &PeopleV2{
+		People: make([]*PersonV2, 0, len(people)),
+		Count:  len(people),
+	}
+	for _, p := range people {
+		result.People = append(result.People, NewPersonV2(p))
+	}
+
+	return result
+}
+
+func (p *PeopleV2) ToJSON(w io.Writer) error {
+
+	return json.NewEncoder(w).Encode(p)
+}
+
+func (p *PeopleV2) FromJSON(r io.Reader) error {
+
+	return DecodeJSON(r, p, false)
+}
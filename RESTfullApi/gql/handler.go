@@ -0,0 +1,205 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/graphql-go/graphql/language/source"
+)
+
+type (
+	Handler struct {
+		logger        *slog.Logger
+		schema        graphql.Schema
+		timeout       time.Duration
+		maxDepth      int
+		maxComplexity int
+		maxBodySize   int64
+		canMutate     func(*http.Request) bool
+	}
+
+	request struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+
+	option func(handler *Handler)
+)
+
+const (
+	setContentType        = "content-type"
+	jsonType              = "application/json"
+	errorValidatingPerson = "Error validating person: %v"
+	errorDecodingRequest  = "Error while decoding the graphql request"
+	errorWrittingResponse = "Error while writing the graphql response"
+	errorTooDeep          = "query depth %d exceeds the limit of %d"
+	errorTooComplex       = "query complexity %d exceeds the limit of %d"
+	errorBodyTooLarge     = "the request body exceeds the limit of %d bytes"
+)
+
+func NewHandler(logger *slog.Logger, schema graphql.Schema, opts ...option) *Handler {
+	handler := &Handler{
+		logger:        logger,
+		schema:        schema,
+		timeout:       10 * time.Second,
+		maxDepth:      10,
+		maxComplexity: 1000,
+		maxBodySize:   1 << 20,
+	}
+
+	for i := range opts {
+		opts[i](handler)
+	}
+
+	return handler
+}
+
+func WithTimeout(timeout time.Duration) option {
+	return func(handler *Handler) {
+		handler.timeout = timeout
+	}
+}
+
+// WithMaxDepth limits how deeply selections can be nested.
+func WithMaxDepth(depth int) option {
+	return func(handler *Handler) {
+		handler.maxDepth = depth
+	}
+}
+
+// WithMutationAuthorizer rejects the mutations of the requests for which
+// canMutate returns false with 403.
+func WithMutationAuthorizer(canMutate func(*http.Request) bool) option {
+	return func(handler *Handler) {
+		handler.canMutate = canMutate
+	}
+}
+
+// WithMaxComplexity limits the estimated number of fields a query resolves.
+func WithMaxComplexity(complexity int) option {
+	return func(handler *Handler) {
+		handler.maxComplexity = complexity
+	}
+}
+
+// WithMaxBodySize bounds the size of the POST bodies, larger ones are
+// rejected with 413.
+func WithMaxBodySize(size int64) option {
+	return func(handler *Handler) {
+		handler.maxBodySize = size
+	}
+}
+
+// ServeHTTP accepts queries as a JSON body on POST and as query parameters
+// on GET, mutations are only allowed on POST.
+func (h *Handler) ServeHTTP(response http.ResponseWriter, httpRequest *http.Request) {
+
+	response.Header().Set(setContentType, jsonType)
+
+	var req request
+
+	switch httpRequest.Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(http.MaxBytesReader(response, httpRequest.Body, h.maxBodySize)).Decode(&req); err != nil {
+			h.logger.InfoContext(httpRequest.Context(), errorDecodingRequest, "error", err)
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				h.writeErrors(response, httpRequest, http.StatusRequestEntityTooLarge, gqlerrors.FormatErrors(fmt.Errorf(errorBodyTooLarge, tooLarge.Limit)))
+				return
+			}
+			h.writeErrors(response, httpRequest, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+			return
+		}
+
+	case http.MethodGet:
+		query := httpRequest.URL.Query()
+		req.Query = query.Get("query")
+		req.OperationName = query.Get("operationName")
+		if variables := query.Get("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				h.logger.InfoContext(httpRequest.Context(), errorDecodingRequest, "error", err)
+				h.writeErrors(response, httpRequest, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+				return
+			}
+		}
+
+	default:
+		response.Header().Set("Allow", "GET, POST")
+		h.writeErrors(response, httpRequest, http.StatusMethodNotAllowed, gqlerrors.FormatErrors(fmt.Errorf("method %s is not allowed", httpRequest.Method)))
+		return
+	}
+
+	document, err := parser.Parse(parser.ParseParams{
+		Source: source.NewSource(&source.Source{Body: []byte(req.Query), Name: "GraphQL request"}),
+	})
+	if err != nil {
+		h.writeErrors(response, httpRequest, http.StatusBadRequest, gqlerrors.FormatErrors(err))
+		return
+	}
+
+	if validation := graphql.ValidateDocument(&h.schema, document, nil); !validation.IsValid {
+		h.writeErrors(response, httpRequest, http.StatusBadRequest, validation.Errors)
+		return
+	}
+
+	op := operation(document, req.OperationName)
+
+	if op != nil {
+		if op.Operation == "mutation" && httpRequest.Method != http.MethodPost {
+			h.writeErrors(response, httpRequest, http.StatusMethodNotAllowed, gqlerrors.FormatErrors(fmt.Errorf("mutations are only allowed on POST")))
+			return
+		}
+
+		if op.Operation == "mutation" && h.canMutate != nil && !h.canMutate(httpRequest) {
+			h.writeErrors(response, httpRequest, http.StatusForbidden, gqlerrors.FormatErrors(fmt.Errorf("mutations are not allowed for this caller")))
+			return
+		}
+
+		depth, complexity := newQueryCost(document, req.Variables).measure(op.SelectionSet)
+
+		if depth > h.maxDepth {
+			h.writeErrors(response, httpRequest, http.StatusBadRequest, gqlerrors.FormatErrors(fmt.Errorf(errorTooDeep, depth, h.maxDepth)))
+			return
+		}
+
+		if complexity > h.maxComplexity {
+			h.writeErrors(response, httpRequest, http.StatusBadRequest, gqlerrors.FormatErrors(fmt.Errorf(errorTooComplex, complexity, h.maxComplexity)))
+			return
+		}
+	}
+
+	// the deadline of the route is usually the shorter one, the execution is
+	// canceled too when the client goes away
+	ctx, cancel := context.WithTimeout(httpRequest.Context(), h.timeout)
+	defer cancel()
+
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           document,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+
+	if err := json.NewEncoder(response).Encode(result); err != nil {
+		h.logger.ErrorContext(httpRequest.Context(), errorWrittingResponse, "error", err)
+	}
+}
+
+func (h *Handler) writeErrors(response http.ResponseWriter, httpRequest *http.Request, status int, errors []gqlerrors.FormattedError) {
+
+	response.WriteHeader(status)
+
+	if err := json.NewEncoder(response).Encode(&graphql.Result{Errors: errors}); err != nil {
+		h.logger.ErrorContext(httpRequest.Context(), errorWrittingResponse, "error", err)
+	}
+}
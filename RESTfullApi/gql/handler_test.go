@@ -0,0 +1,233 @@
+package gql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"github.com/graphql-go/graphql/language/parser"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// memoryStore keeps people in insertion order, which is also id order.
+type memoryStore struct {
+	people data.People
+}
+
+func (m *memoryStore) GetByID(_ context.Context, id primitive.ObjectID) (*data.Person, error) {
+	for _, p := range m.people {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return nil, data.ErrNotFound
+}
+
+func (m *memoryStore) GetByIDs(ctx context.Context, ids []primitive.ObjectID) (data.People, error) {
+	var people data.People
+	for _, id := range ids {
+		if p, err := m.GetByID(ctx, id); err == nil {
+			people = append(people, p)
+		}
+	}
+	return people, nil
+}
+
+func (m *memoryStore) FindByName(_ context.Context, name string) (data.People, error) {
+	return m.List(context.Background(), store.ListOptions{Firstname: name})
+}
+
+func (m *memoryStore) List(_ context.Context, opts store.ListOptions) (data.People, error) {
+	var people data.People
+	for _, p := range m.people {
+		if opts.Firstname != "" && !strings.EqualFold(p.Firstname, opts.Firstname) {
+			continue
+		}
+		if !opts.After.IsZero() && p.ID.Hex() <= opts.After.Hex() {
+			continue
+		}
+		if opts.Limit > 0 && int64(len(people)) == opts.Limit {
+			break
+		}
+		people = append(people, p)
+	}
+	return people, nil
+}
+
+func (m *memoryStore) Create(_ context.Context, person data.Person) (primitive.ObjectID, error) {
+	person.ID = primitive.NewObjectID()
+	m.people = append(m.people, &person)
+	return person.ID, nil
+}
+
+func (m *memoryStore) Update(_ context.Context, id primitive.ObjectID, update data.PersonUpdate) (int64, error) {
+	return 0, nil
+}
+
+func (m *memoryStore) Delete(_ context.Context, id primitive.ObjectID) (int64, error) {
+	return 0, nil
+}
+
+func newTestHandler(t *testing.T, opts ...option) (*Handler, *memoryStore) {
+
+	people := &memoryStore{}
+
+	for _, name := range []string{"Ada", "Grace", "Linus"} {
+		if _, err := people.Create(context.Background(), data.Person{Firstname: name, Lastname: "Test"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	schema, err := NewSchema(people)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return NewHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), schema, opts...), people
+}
+
+func doQuery(t *testing.T, handler http.Handler, query string, variables map[string]interface{}) (int, map[string]interface{}) {
+
+	body, err := json.Marshal(request{Query: query, Variables: variables})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body))))
+
+	var result map[string]interface{}
+	if err := json.NewDecoder(recorder.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+
+	return recorder.Code, result
+}
+
+func TestPeoplePagination(t *testing.T) {
+
+	handler, _ := newTestHandler(t)
+
+	const query = `query($after: String) {
+		people(first: 2, after: $after) {
+			edges { node { firstName } }
+			pageInfo { hasNextPage endCursor }
+		}
+	}`
+
+	status, result := doQuery(t, handler, query, nil)
+	if status != http.StatusOK || result["errors"] != nil {
+		t.Fatalf("unexpected response %d: %v", status, result)
+	}
+
+	people := result["data"].(map[string]interface{})["people"].(map[string]interface{})
+	if edges := people["edges"].([]interface{}); len(edges) != 2 {
+		t.Fatalf("expected 2 edges, got %d", len(edges))
+	}
+
+	info := people["pageInfo"].(map[string]interface{})
+	if info["hasNextPage"] != true {
+		t.Fatal("expected a next page")
+	}
+
+	_, result = doQuery(t, handler, query, map[string]interface{}{"after": info["endCursor"]})
+
+	people = result["data"].(map[string]interface{})["people"].(map[string]interface{})
+	edges := people["edges"].([]interface{})
+	if len(edges) != 1 || edges[0].(map[string]interface{})["node"].(map[string]interface{})["firstName"] != "Linus" {
+		t.Fatalf("unexpected second page: %v", edges)
+	}
+}
+
+func TestCreatePersonValidates(t *testing.T) {
+
+	handler, people := newTestHandler(t)
+
+	_, result := doQuery(t, handler, `mutation { createPerson(input: {firstName: "N0t valid", lastName: "Test"}) { id } }`, nil)
+
+	if result["errors"] == nil {
+		t.Fatal("expected a validation error")
+	}
+
+	if len(people.people) != 3 {
+		t.Fatalf("invalid person was stored")
+	}
+}
+
+func TestQueryLimits(t *testing.T) {
+
+	handler, _ := newTestHandler(t, WithMaxDepth(3), WithMaxComplexity(50))
+
+	status, _ := doQuery(t, handler, `{ people(first: 1) { edges { node { id } } } }`, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("expected depth limit to reject the query, got %d", status)
+	}
+
+	status, _ = doQuery(t, handler, `{ people(first: 100) { pageInfo { hasNextPage } } }`, nil)
+	if status != http.StatusBadRequest {
+		t.Fatalf("expected complexity limit to reject the query, got %d", status)
+	}
+
+	status, _ = doQuery(t, handler, `{ people(first: 10) { pageInfo { hasNextPage } } }`, nil)
+	if status != http.StatusOK {
+		t.Fatalf("expected query within limits to succeed, got %d", status)
+	}
+}
+
+func TestQueryCostFragments(t *testing.T) {
+
+	// each fragment spreads the previous one twice, 2^40 spreads if they
+	// were measured every time
+	var query strings.Builder
+	query.WriteString(`query { person(id: "1") { ...F40 } } fragment F0 on Person { id }`)
+	for i := 1; i <= 40; i++ {
+		fmt.Fprintf(&query, ` fragment F%d on Person { ...F%d ...F%d }`, i, i-1, i-1)
+	}
+
+	depth, complexity := measure(t, query.String(), nil)
+	if depth != 2 {
+		t.Fatalf("expected depth 2, got %d", depth)
+	}
+	if complexity != maxCost {
+		t.Fatalf("expected the complexity to saturate at %d, got %d", maxCost, complexity)
+	}
+}
+
+func TestQueryCostPageCap(t *testing.T) {
+
+	query := `query($first: Int) { people(first: $first) { edges { node { id } } } }`
+
+	_, complexity := measure(t, query, map[string]interface{}{"first": float64(1 << 62)})
+	if want := 1 + maxPageSize*3; complexity != want {
+		t.Fatalf("expected first to be clamped to the page size, complexity %d, got %d", want, complexity)
+	}
+}
+
+func measure(t *testing.T, query string, variables map[string]interface{}) (int, int) {
+
+	document, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return newQueryCost(document, variables).measure(operation(document, "").SelectionSet)
+}
+
+func TestMaxBodySize(t *testing.T) {
+
+	handler, _ := newTestHandler(t, WithMaxBodySize(16))
+
+	status, _ := doQuery(t, handler, `{ people(first: 1) { pageInfo { hasNextPage } } }`, nil)
+	if status != http.StatusRequestEntityTooLarge {
+		t.Fatalf("expected a body over the limit to be rejected with 413, got %d", status)
+	}
+}
@@ -0,0 +1,192 @@
+package gql
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// queryCost walks the selected operation and returns its depth and
+// complexity. Every field costs one, the cost of the fields below a list
+// field is multiplied by the number of items it can return.
+type (
+	queryCost struct {
+		fragments map[string]*ast.FragmentDefinition
+		variables map[string]interface{}
+		measured  map[string]cost
+	}
+
+	cost struct {
+		depth      int
+		complexity int
+	}
+)
+
+// maxCost bounds the complexity so that nested list fields can't overflow it,
+// it is far beyond any limit a handler is configured with.
+const maxCost = math.MaxInt32
+
+func newQueryCost(document *ast.Document, variables map[string]interface{}) *queryCost {
+
+	fragments := make(map[string]*ast.FragmentDefinition)
+
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	return &queryCost{fragments: fragments, variables: variables, measured: make(map[string]cost)}
+}
+
+// operation returns the operation that will be executed, nil if it can't be
+// determined, which the executor reports on its own.
+func operation(document *ast.Document, operationName string) *ast.OperationDefinition {
+
+	var found *ast.OperationDefinition
+
+	for _, definition := range document.Definitions {
+		op, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+
+		if operationName == "" {
+			if found != nil {
+				return nil
+			}
+			found = op
+			continue
+		}
+
+		if op.Name != nil && op.Name.Value == operationName {
+			return op
+		}
+	}
+
+	return found
+}
+
+func (q *queryCost) measure(selectionSet *ast.SelectionSet) (depth int, complexity int) {
+
+	if selectionSet == nil {
+		return 0, 0
+	}
+
+	for _, selection := range selectionSet.Selections {
+
+		var childDepth, childComplexity int
+
+		switch s := selection.(type) {
+		case *ast.Field:
+			depth, complexity := q.measure(s.SelectionSet)
+			childDepth = depth + 1
+			childComplexity = add(1, multiply(q.multiplier(s), complexity))
+
+		case *ast.InlineFragment:
+			childDepth, childComplexity = q.measure(s.SelectionSet)
+
+		case *ast.FragmentSpread:
+			childDepth, childComplexity = q.spread(s.Name.Value)
+		}
+
+		depth = max(depth, childDepth)
+		complexity = add(complexity, childComplexity)
+	}
+
+	return depth, complexity
+}
+
+// spread measures a fragment once, however many times it is spread, so that
+// fragments spreading each other several times don't cost exponential time.
+func (q *queryCost) spread(name string) (depth int, complexity int) {
+
+	if measured, ok := q.measured[name]; ok {
+		return measured.depth, measured.complexity
+	}
+
+	fragment, ok := q.fragments[name]
+	if !ok {
+		return 0, 0
+	}
+
+	// the validation rejects the cycles, a fragment being measured counts
+	// for nothing if one is left anyway
+	q.measured[name] = cost{}
+
+	depth, complexity = q.measure(fragment.SelectionSet)
+	q.measured[name] = cost{depth: depth, complexity: complexity}
+
+	return depth, complexity
+}
+
+// multiplier estimates how many items a field returns from its paging
+// arguments.
+func (q *queryCost) multiplier(field *ast.Field) int {
+
+	for _, argument := range field.Arguments {
+		switch argument.Name.Value {
+		case "first":
+			if n, ok := q.intValue(argument.Value); ok && n > 0 {
+				// the resolvers never return more than a page
+				return min(n, maxPageSize)
+			}
+			return defaultPageSize
+
+		case "ids":
+			if list, ok := argument.Value.(*ast.ListValue); ok {
+				return max(len(list.Values), 1)
+			}
+			if values, ok := q.variable(argument.Value).([]interface{}); ok {
+				return max(len(values), 1)
+			}
+		}
+	}
+
+	if field.Name.Value == "people" {
+		return defaultPageSize
+	}
+
+	return 1
+}
+
+func (q *queryCost) intValue(value ast.Value) (int, bool) {
+
+	if literal, ok := value.(*ast.IntValue); ok {
+		n, err := strconv.Atoi(literal.Value)
+		return n, err == nil
+	}
+
+	switch v := q.variable(value).(type) {
+	case float64:
+		return int(v), true
+	case int:
+		return v, true
+	}
+
+	return 0, false
+}
+
+func (q *queryCost) variable(value ast.Value) interface{} {
+
+	variable, ok := value.(*ast.Variable)
+	if !ok {
+		return nil
+	}
+
+	return q.variables[variable.Name.Value]
+}
+
+func add(a, b int) int {
+	return min(a+b, maxCost)
+}
+
+func multiply(a, b int) int {
+
+	if a != 0 && b > maxCost/a {
+		return maxCost
+	}
+
+	return min(a*b, maxCost)
+}
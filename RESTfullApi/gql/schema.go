@@ -0,0 +1,405 @@
+package gql
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"github.com/graphql-go/graphql"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	cursorPrefix    = "person:"
+)
+
+var errInvalidCursor = errors.New("invalid cursor")
+
+type (
+	edge struct {
+		Cursor string
+		Node   *data.Person
+	}
+
+	pageInfo struct {
+		HasNextPage     bool
+		HasPreviousPage bool
+		StartCursor     string
+		EndCursor       string
+	}
+
+	connection struct {
+		Edges    []edge
+		PageInfo pageInfo
+	}
+)
+
+func encodeCursor(id primitive.ObjectID) string {
+	return base64.StdEncoding.EncodeToString([]byte(cursorPrefix + id.Hex()))
+}
+
+func decodeCursor(cursor string) (primitive.ObjectID, error) {
+
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return primitive.NilObjectID, errInvalidCursor
+	}
+
+	hex, ok := strings.CutPrefix(string(raw), cursorPrefix)
+	if !ok {
+		return primitive.NilObjectID, errInvalidCursor
+	}
+
+	id, err := primitive.ObjectIDFromHex(hex)
+	if err != nil {
+		return primitive.NilObjectID, errInvalidCursor
+	}
+
+	return id, nil
+}
+
+// NewSchema builds the people schema on top of the store shared with the
+// REST handlers.
+func NewSchema(people store.PersonStore) (graphql.Schema, error) {
+
+	personType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Person",
+		Fields: graphql.Fields{
+			"id": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.ID),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*data.Person).ID.Hex(), nil
+				},
+			},
+			"firstName": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*data.Person).Firstname, nil
+				},
+			},
+			"lastName": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*data.Person).Lastname, nil
+				},
+			},
+		},
+	})
+
+	edgeType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PersonEdge",
+		Fields: graphql.Fields{
+			"cursor": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.String),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(edge).Cursor, nil
+				},
+			},
+			"node": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(edge).Node, nil
+				},
+			},
+		},
+	})
+
+	pageInfoType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PageInfo",
+		Fields: graphql.Fields{
+			"hasNextPage": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(pageInfo).HasNextPage, nil
+				},
+			},
+			"hasPreviousPage": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.Boolean),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(pageInfo).HasPreviousPage, nil
+				},
+			},
+			"startCursor": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullable(p.Source.(pageInfo).StartCursor), nil
+				},
+			},
+			"endCursor": &graphql.Field{
+				Type: graphql.String,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nullable(p.Source.(pageInfo).EndCursor), nil
+				},
+			},
+		},
+	})
+
+	connectionType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "PersonConnection",
+		Fields: graphql.Fields{
+			"edges": &graphql.Field{
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(edgeType))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).Edges, nil
+				},
+			},
+			"pageInfo": &graphql.Field{
+				Type: graphql.NewNonNull(pageInfoType),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(*connection).PageInfo, nil
+				},
+			},
+		},
+	})
+
+	filterType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "PersonFilter",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"firstName": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	createInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreatePersonInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"firstName": &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+			"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.NewNonNull(graphql.String)},
+		},
+	})
+
+	updateInputType := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "UpdatePersonInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"firstName": &graphql.InputObjectFieldConfig{Type: graphql.String},
+			"lastName":  &graphql.InputObjectFieldConfig{Type: graphql.String},
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"person": &graphql.Field{
+				Type: personType,
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid id: %w", err)
+					}
+
+					person, err := people.GetByID(p.Context, id)
+					if err == data.ErrNotFound {
+						return nil, nil
+					}
+
+					return person, err
+				},
+			},
+			"peopleByIds": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(personType)),
+				Description: "Batch lookup, the result has one entry per id and null for unknown ids.",
+				Args: graphql.FieldConfigArgument{
+					"ids": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.ID)))},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					rawIDs := p.Args["ids"].([]interface{})
+					ids := make([]primitive.ObjectID, 0, len(rawIDs))
+					for _, raw := range rawIDs {
+						id, err := primitive.ObjectIDFromHex(raw.(string))
+						if err != nil {
+							return nil, fmt.Errorf("invalid id %q: %w", raw, err)
+						}
+						ids = append(ids, id)
+					}
+
+					found, err := people.GetByIDs(p.Context, ids)
+					if err != nil {
+						return nil, err
+					}
+
+					byID := make(map[primitive.ObjectID]*data.Person, len(found))
+					for _, person := range found {
+						byID[person.ID] = person
+					}
+
+					result := make([]*data.Person, 0, len(ids))
+					for _, id := range ids {
+						result = append(result, byID[id])
+					}
+
+					return result, nil
+				},
+			},
+			"people": &graphql.Field{
+				Type: graphql.NewNonNull(connectionType),
+				Args: graphql.FieldConfigArgument{
+					"filter": &graphql.ArgumentConfig{Type: filterType},
+					"first":  &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultPageSize},
+					"after":  &graphql.ArgumentConfig{Type: graphql.String},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, _ := p.Args["first"].(int)
+					if first <= 0 || first > maxPageSize {
+						return nil, fmt.Errorf("first must be between 1 and %d", maxPageSize)
+					}
+
+					opts := store.ListOptions{Limit: int64(first) + 1}
+
+					if filter, ok := p.Args["filter"].(map[string]interface{}); ok {
+						opts.Firstname, _ = filter["firstName"].(string)
+						opts.Lastname, _ = filter["lastName"].(string)
+					}
+
+					if after, ok := p.Args["after"].(string); ok && after != "" {
+						id, err := decodeCursor(after)
+						if err != nil {
+							return nil, err
+						}
+						opts.After = id
+					}
+
+					found, err := people.List(p.Context, opts)
+					if err != nil {
+						return nil, err
+					}
+
+					return newConnection(found, first, !opts.After.IsZero()), nil
+				},
+			},
+		},
+	})
+
+	mutationType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createPerson": &graphql.Field{
+				Type: graphql.NewNonNull(personType),
+				Args: graphql.FieldConfigArgument{
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(createInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					input := p.Args["input"].(map[string]interface{})
+
+					person := data.Person{}
+					person.Firstname, _ = input["firstName"].(string)
+					person.Lastname, _ = input["lastName"].(string)
+
+					if err := person.Validate(); err != nil {
+						return nil, fmt.Errorf(errorValidatingPerson, err)
+					}
+
+					id, err := people.Create(p.Context, person)
+					if err != nil {
+						return nil, err
+					}
+					person.ID = id
+
+					return &person, nil
+				},
+			},
+			"updatePerson": &graphql.Field{
+				Type:        personType,
+				Description: "Returns the updated person or null when no person has the id.",
+				Args: graphql.FieldConfigArgument{
+					"id":    &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+					"input": &graphql.ArgumentConfig{Type: graphql.NewNonNull(updateInputType)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid id: %w", err)
+					}
+
+					input := p.Args["input"].(map[string]interface{})
+
+					update := data.PersonUpdate{}
+					update.Firstname, _ = input["firstName"].(string)
+					update.Lastname, _ = input["lastName"].(string)
+
+					if err := update.Validate(); err != nil {
+						return nil, fmt.Errorf(errorValidatingPerson, err)
+					}
+
+					if _, err := people.Update(p.Context, id, update); err != nil {
+						return nil, err
+					}
+
+					person, err := people.GetByID(p.Context, id)
+					if err == data.ErrNotFound {
+						return nil, nil
+					}
+
+					return person, err
+				},
+			},
+			"deletePerson": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Returns false when no person has the id.",
+				Args: graphql.FieldConfigArgument{
+					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id, err := primitive.ObjectIDFromHex(p.Args["id"].(string))
+					if err != nil {
+						return nil, fmt.Errorf("invalid id: %w", err)
+					}
+
+					deleted, err := people.Delete(p.Context, id)
+					if err != nil {
+						return nil, err
+					}
+
+					return deleted > 0, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{
+		Query:    queryType,
+		Mutation: mutationType,
+	})
+}
+
+// newConnection turns a page fetched with one extra person into a Relay
+// connection, the extra person only tells whether there is a next page.
+func newConnection(people data.People, first int, hasPrevious bool) *connection {
+
+	conn := &connection{
+		Edges:    make([]edge, 0, first),
+		PageInfo: pageInfo{HasPreviousPage: hasPrevious},
+	}
+
+	if len(people) > first {
+		conn.PageInfo.HasNextPage = true
+		people = people[:first]
+	}
+
+	for _, person := range people {
+		conn.Edges = append(conn.Edges, edge{Cursor: encodeCursor(person.ID), Node: person})
+	}
+
+	if len(conn.Edges) > 0 {
+		conn.PageInfo.StartCursor = conn.Edges[0].Cursor
+		conn.PageInfo.EndCursor = conn.Edges[len(conn.Edges)-1].Cursor
+	}
+
+	return conn
+}
+
+func nullable(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+
+	return value
+}
@@ -0,0 +1,71 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/observability"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/peoplepb"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// MethodScopes maps the methods of the PersonService to the API key scope
+// they require.
+var MethodScopes = map[string]auth.Scope{
+	peoplepb.PersonService_GetPerson_FullMethodName:    auth.ScopeRead,
+	peoplepb.PersonService_ListPeople_FullMethodName:   auth.ScopeRead,
+	peoplepb.PersonService_WatchPeople_FullMethodName:  auth.ScopeRead,
+	peoplepb.PersonService_CreatePerson_FullMethodName: auth.ScopeWrite,
+	peoplepb.PersonService_UpdatePerson_FullMethodName: auth.ScopeWrite,
+	peoplepb.PersonService_DeletePerson_FullMethodName: auth.ScopeWrite,
+}
+
+// NewServer returns a gRPC server exposing personServer along with the
+// standard health and reflection services, instrumented with the
+// observability interceptors. opts can chain more interceptors, they run
+// after the observability ones.
+func NewServer(personServer *PersonServer, opts ...grpc.ServerOption) (*grpc.Server, *health.Server) {
+
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(observability.UnaryServerInterceptor),
+		grpc.ChainStreamInterceptor(observability.StreamServerInterceptor),
+	}, opts...)
+
+	server := grpc.NewServer(opts...)
+
+	peoplepb.RegisterPersonServiceServer(server, personServer)
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(peoplepb.PersonService_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(server, healthServer)
+
+	reflection.Register(server)
+
+	return server, healthServer
+}
+
+// Stop marks every service as not serving and waits until ctx is done for the
+// running calls to end before closing the remaining ones, like WatchPeople
+// streams, and then returns the error of ctx.
+func Stop(ctx context.Context, server *grpc.Server, healthServer *health.Server) error {
+
+	healthServer.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		server.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+		return nil
+	case <-ctx.Done():
+		server.Stop()
+		return ctx.Err()
+	}
+}
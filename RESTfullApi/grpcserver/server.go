@@ -0,0 +1,267 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/peoplepb"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type (
+	// PersonServer implements peoplepb.PersonServiceServer on the store and
+	// validation shared with the REST handlers.
+	PersonServer struct {
+		peoplepb.UnimplementedPersonServiceServer
+
+		logger  *slog.Logger
+		store   store.PersonStore
+		timeout time.Duration
+	}
+
+	option func(server *PersonServer)
+)
+
+const (
+	defaultBatchSize = 100
+	// maxBatchSize caps the batches as the pages of the REST API are
+	maxBatchSize = 1000
+
+	errorValidatingPerson = "Error validating person: %v"
+	errorParsingID        = "Error while parsing the id: %v"
+	noIDFound             = "No Person was found with the id: %v"
+	errorStore            = "Error while calling the store"
+	errorWatching         = "Error while watching people"
+)
+
+func NewPersonServer(logger *slog.Logger, store store.PersonStore, opts ...option) *PersonServer {
+	server := &PersonServer{
+		logger:  logger,
+		store:   store,
+		timeout: 5 * time.Second,
+	}
+
+	for i := range opts {
+		opts[i](server)
+	}
+
+	return server
+}
+
+func WithTimeout(timeout time.Duration) option {
+	return func(server *PersonServer) {
+		server.timeout = timeout
+	}
+}
+
+func (s *PersonServer) GetPerson(ctx context.Context, req *peoplepb.GetPersonRequest) (*peoplepb.Person, error) {
+
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	person, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, s.storeError(ctx, "GetPerson", id, err)
+	}
+
+	return toProto(person), nil
+}
+
+func (s *PersonServer) ListPeople(req *peoplepb.ListPeopleRequest, stream peoplepb.PersonService_ListPeopleServer) error {
+
+	batchSize := int64(req.GetBatchSize())
+	if batchSize <= 0 {
+		batchSize = defaultBatchSize
+	}
+	batchSize = min(batchSize, maxBatchSize)
+
+	opts := store.ListOptions{
+		Firstname: req.GetFirstName(),
+		Lastname:  req.GetLastName(),
+		Limit:     batchSize,
+	}
+
+	for {
+		ctx, cancel := context.WithTimeout(stream.Context(), s.timeout)
+		people, err := s.store.List(ctx, opts)
+		cancel()
+
+		if err != nil {
+			return s.storeError(stream.Context(), "ListPeople", primitive.NilObjectID, err)
+		}
+
+		for _, person := range people {
+			if err := stream.Send(toProto(person)); err != nil {
+				return err
+			}
+		}
+
+		if int64(len(people)) < batchSize {
+			return nil
+		}
+
+		opts.After = people[len(people)-1].ID
+	}
+}
+
+func (s *PersonServer) CreatePerson(ctx context.Context, req *peoplepb.CreatePersonRequest) (*peoplepb.Person, error) {
+
+	person := data.Person{
+		Firstname: req.GetFirstName(),
+		Lastname:  req.GetLastName(),
+	}
+
+	if err := person.Validate(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, errorValidatingPerson, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	id, err := s.store.Create(ctx, person)
+	if err != nil {
+		return nil, s.storeError(ctx, "CreatePerson", primitive.NilObjectID, err)
+	}
+	person.ID = id
+
+	return toProto(&person), nil
+}
+
+func (s *PersonServer) UpdatePerson(ctx context.Context, req *peoplepb.UpdatePersonRequest) (*peoplepb.Person, error) {
+
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	update := data.PersonUpdate{
+		Firstname: req.GetFirstName(),
+		Lastname:  req.GetLastName(),
+	}
+
+	if err := update.Validate(); err != nil {
+		return nil, status.Errorf(codes.InvalidArgument, errorValidatingPerson, err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	if _, err := s.store.Update(ctx, id, update); err != nil {
+		return nil, s.storeError(ctx, "UpdatePerson", id, err)
+	}
+
+	person, err := s.store.GetByID(ctx, id)
+	if err != nil {
+		return nil, s.storeError(ctx, "UpdatePerson", id, err)
+	}
+
+	return toProto(person), nil
+}
+
+func (s *PersonServer) DeletePerson(ctx context.Context, req *peoplepb.DeletePersonRequest) (*peoplepb.DeletePersonResponse, error) {
+
+	id, err := parseID(req.GetId())
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	deleted, err := s.store.Delete(ctx, id)
+	if err != nil {
+		return nil, s.storeError(ctx, "DeletePerson", id, err)
+	}
+
+	if deleted == 0 {
+		return nil, status.Errorf(codes.NotFound, noIDFound, id.Hex())
+	}
+
+	return &peoplepb.DeletePersonResponse{}, nil
+}
+
+// WatchPeople streams changes until the client goes away, it needs a store
+// implementing store.PersonWatcher.
+func (s *PersonServer) WatchPeople(_ *peoplepb.WatchPeopleRequest, stream peoplepb.PersonService_WatchPeopleServer) error {
+
+	watcher, ok := s.store.(store.PersonWatcher)
+	if !ok {
+		return status.Error(codes.Unimplemented, "the store can't watch people")
+	}
+
+	err := watcher.Watch(stream.Context(), func(change store.PersonChange) error {
+		event := &peoplepb.PersonEvent{Person: &peoplepb.Person{Id: change.ID.Hex()}}
+
+		switch change.Type {
+		case store.Created:
+			event.Type = peoplepb.PersonEvent_TYPE_CREATED
+		case store.Updated:
+			event.Type = peoplepb.PersonEvent_TYPE_UPDATED
+		case store.Deleted:
+			event.Type = peoplepb.PersonEvent_TYPE_DELETED
+		}
+
+		if change.Person != nil {
+			event.Person = toProto(change.Person)
+		}
+
+		return stream.Send(event)
+	})
+
+	if stream.Context().Err() != nil {
+		return status.FromContextError(stream.Context().Err()).Err()
+	}
+
+	if err != nil {
+		s.logger.ErrorContext(stream.Context(), errorWatching, "error", err)
+		return status.Error(codes.Unavailable, err.Error())
+	}
+
+	return nil
+}
+
+func (s *PersonServer) storeError(ctx context.Context, method string, id primitive.ObjectID, err error) error {
+
+	if err == data.ErrNotFound {
+		return status.Errorf(codes.NotFound, noIDFound, id.Hex())
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
+		return status.FromContextError(err).Err()
+	}
+
+	s.logger.ErrorContext(ctx, errorStore, "method", method, "error", err)
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func parseID(value string) (primitive.ObjectID, error) {
+
+	id, err := primitive.ObjectIDFromHex(value)
+	if err != nil {
+		return primitive.NilObjectID, status.Errorf(codes.InvalidArgument, errorParsingID, err)
+	}
+
+	return id, nil
+}
+
+func toProto(person *data.Person) *peoplepb.Person {
+
+	return &peoplepb.Person{
+		Id:        person.ID.Hex(),
+		FirstName: person.Firstname,
+		LastName:  person.Lastname,
+	}
+}
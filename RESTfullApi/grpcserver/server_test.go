@@ -0,0 +1,192 @@
+package grpcserver
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/peoplepb"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// memoryStore keeps people ordered by id, err fails every call when set.
+type memoryStore struct {
+	store.PersonStore
+
+	people data.People
+	lists  []store.ListOptions
+	err    error
+}
+
+func (s *memoryStore) GetByID(ctx context.Context, id primitive.ObjectID) (*data.Person, error) {
+
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	for _, person := range s.people {
+		if person.ID == id {
+			return person, nil
+		}
+	}
+
+	return nil, data.ErrNotFound
+}
+
+func (s *memoryStore) List(ctx context.Context, opts store.ListOptions) (data.People, error) {
+
+	s.lists = append(s.lists, opts)
+	if s.err != nil {
+		return nil, s.err
+	}
+
+	var people data.People
+	for _, person := range s.people {
+		if !opts.After.IsZero() && person.ID.Hex() <= opts.After.Hex() {
+			continue
+		}
+		if int64(len(people)) == opts.Limit {
+			break
+		}
+		people = append(people, person)
+	}
+
+	return people, nil
+}
+
+func (s *memoryStore) Create(ctx context.Context, person data.Person) (primitive.ObjectID, error) {
+	return primitive.NewObjectID(), s.err
+}
+
+func (s *memoryStore) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+	return 0, s.err
+}
+
+// listStream collects the people sent by ListPeople.
+type listStream struct {
+	grpc.ServerStream
+
+	sent []*peoplepb.Person
+}
+
+func (s *listStream) Context() context.Context {
+	return context.Background()
+}
+
+func (s *listStream) Send(person *peoplepb.Person) error {
+	s.sent = append(s.sent, person)
+	return nil
+}
+
+func TestListPeople(t *testing.T) {
+
+	people := &memoryStore{}
+	for i := 0; i < 5; i++ {
+		people.people = append(people.people, &data.Person{ID: primitive.NewObjectIDFromTimestamp(time.Unix(int64(i), 0)), Firstname: "David"})
+	}
+
+	server := NewPersonServer(slog.New(slog.NewTextHandler(io.Discard, nil)), people)
+
+	tests := []struct {
+		name      string
+		batchSize int32
+		limits    []int64
+	}{
+		{"default batch", 0, []int64{defaultBatchSize}},
+		{"pages", 2, []int64{2, 2, 2}},
+		{"capped batch", 5000, []int64{maxBatchSize}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			people.lists = nil
+			stream := &listStream{}
+
+			if err := server.ListPeople(&peoplepb.ListPeopleRequest{BatchSize: test.batchSize}, stream); err != nil {
+				t.Fatal(err)
+			}
+
+			if len(stream.sent) != len(people.people) {
+				t.Fatalf("expected %d people, got %d", len(people.people), len(stream.sent))
+			}
+			for i, person := range stream.sent {
+				if person.GetId() != people.people[i].ID.Hex() {
+					t.Errorf("person %d: expected %s, got %s", i, people.people[i].ID.Hex(), person.GetId())
+				}
+			}
+
+			if len(people.lists) != len(test.limits) {
+				t.Fatalf("expected %d store calls, got %d", len(test.limits), len(people.lists))
+			}
+			for i, opts := range people.lists {
+				if opts.Limit != test.limits[i] {
+					t.Errorf("call %d: expected limit %d, got %d", i, test.limits[i], opts.Limit)
+				}
+			}
+		})
+	}
+}
+
+func TestStatusCodes(t *testing.T) {
+
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	unknown := primitive.NewObjectID().Hex()
+
+	tests := []struct {
+		name string
+		err  error
+		call func(server *PersonServer) error
+		code codes.Code
+	}{
+		{"invalid id", nil, func(server *PersonServer) error {
+			_, err := server.GetPerson(context.Background(), &peoplepb.GetPersonRequest{Id: "nope"})
+			return err
+		}, codes.InvalidArgument},
+		{"unknown person", nil, func(server *PersonServer) error {
+			_, err := server.GetPerson(context.Background(), &peoplepb.GetPersonRequest{Id: unknown})
+			return err
+		}, codes.NotFound},
+		{"invalid person", nil, func(server *PersonServer) error {
+			_, err := server.CreatePerson(context.Background(), &peoplepb.CreatePersonRequest{FirstName: "David"})
+			return err
+		}, codes.InvalidArgument},
+		{"valid person", nil, func(server *PersonServer) error {
+			_, err := server.CreatePerson(context.Background(), &peoplepb.CreatePersonRequest{FirstName: "David", LastName: "Hernandez"})
+			return err
+		}, codes.OK},
+		{"invalid update", nil, func(server *PersonServer) error {
+			_, err := server.UpdatePerson(context.Background(), &peoplepb.UpdatePersonRequest{Id: unknown})
+			return err
+		}, codes.InvalidArgument},
+		{"delete unknown person", nil, func(server *PersonServer) error {
+			_, err := server.DeletePerson(context.Background(), &peoplepb.DeletePersonRequest{Id: unknown})
+			return err
+		}, codes.NotFound},
+		{"store down", errors.New("connection refused"), func(server *PersonServer) error {
+			_, err := server.GetPerson(context.Background(), &peoplepb.GetPersonRequest{Id: unknown})
+			return err
+		}, codes.Internal},
+		{"deadline", context.DeadlineExceeded, func(server *PersonServer) error {
+			return server.ListPeople(&peoplepb.ListPeopleRequest{}, &listStream{})
+		}, codes.DeadlineExceeded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := NewPersonServer(logger, &memoryStore{err: test.err})
+
+			if code := status.Code(test.call(server)); code != test.code {
+				t.Fatalf("expected %v, got %v", test.code, code)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"sync/atomic"
+)
+
+// CORS lets the browsers of the allowed origins call the API, answering their
+// preflight requests. The origins can be changed while serving.
+type CORS struct {
+	origins atomic.Pointer[[]string]
+}
+
+const (
+	originHeader        = "Origin"
+	varyHeader          = "Vary"
+	requestMethodHeader = "Access-Control-Request-Method"
+	requestHeaders      = "Access-Control-Request-Headers"
+	allowOriginHeader   = "Access-Control-Allow-Origin"
+	allowMethodsHeader  = "Access-Control-Allow-Methods"
+	allowHeadersHeader  = "Access-Control-Allow-Headers"
+	exposeHeadersHeader = "Access-Control-Expose-Headers"
+	maxAgeHeader        = "Access-Control-Max-Age"
+
+	allowedMethods = "GET, POST, PUT, DELETE"
+	exposedHeaders = "Location, Retry-After, RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset, RateLimit-Policy, Idempotent-Replayed, Deprecation, Sunset, Link, X-Request-ID"
+)
+
+// NewCORS allows origins, * allows any origin and no origin disables CORS.
+func NewCORS(origins []string) *CORS {
+
+	cors := &CORS{}
+	cors.SetOrigins(origins)
+
+	return cors
+}
+
+func (c *CORS) SetOrigins(origins []string) {
+	c.origins.Store(&origins)
+}
+
+// Handler wraps the whole router, the preflight requests match no route.
+func (c *CORS) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		response.Header().Add(varyHeader, originHeader)
+
+		origin := request.Header.Get(originHeader)
+		if origin == "" || !c.allowed(origin) {
+			next.ServeHTTP(response, request)
+			return
+		}
+
+		header := response.Header()
+		header.Set(allowOriginHeader, origin)
+		header.Set(exposeHeadersHeader, exposedHeaders)
+
+		if request.Method != http.MethodOptions || request.Header.Get(requestMethodHeader) == "" {
+			next.ServeHTTP(response, request)
+			return
+		}
+
+		header.Set(allowMethodsHeader, allowedMethods)
+		if requested := request.Header.Get(requestHeaders); requested != "" {
+			header.Set(allowHeadersHeader, requested)
+		}
+		header.Set(maxAgeHeader, "600")
+		response.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func (c *CORS) allowed(origin string) bool {
+
+	for _, allowed := range *c.origins.Load() {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type (
+	// problem is an RFC 9457 problem details body.
+	problem struct {
+		Type   string `json:"type"`
+		Title  string `json:"title"`
+		Status int    `json:"status"`
+		Detail string `json:"detail,omitempty"`
+	}
+
+	// routeTimeouts holds the deadline of each named route and the fallback
+	// one of the others.
+	routeTimeouts struct {
+		routes   map[string]time.Duration
+		fallback time.Duration
+	}
+
+	keyTimeout struct{}
+)
+
+const (
+	// TimeoutHeader lets a client shorten the deadline of its request, as a
+	// duration such as 1.5s or a number of seconds. The deadline of a route
+	// can't be extended.
+	TimeoutHeader = "X-Request-Timeout"
+
+	problemType          = "application/problem+json"
+	errorTimeoutHeader   = "Invalid %s header %q, expected a positive duration such as 1.5s"
+	errorParsingTimeout  = "Error while parsing the timeout header"
+	errorDeadline        = "The request did not complete within %v"
+	requestTimedOut      = "The request timed out"
+	errorWrittingProblem = "Error while writing the problem response"
+)
+
+var errTimeoutNotPositive = errors.New("the timeout must be positive")
+
+// WithRouteTimeouts sets the deadline of the requests of each named route,
+// the routes left out get fallback. Without it, only the TimeoutHeader
+// bounds the requests.
+func WithRouteTimeouts(timeouts map[string]time.Duration, fallback time.Duration) option {
+	return func(handler *EndpointHandler) {
+		handler.SetRouteTimeouts(timeouts, fallback)
+	}
+}
+
+// SetRouteTimeouts changes the deadlines of the named routes and of the
+// others for the next requests.
+func (c *EndpointHandler) SetRouteTimeouts(timeouts map[string]time.Duration, fallback time.Duration) {
+	c.routeTimeouts.Store(&routeTimeouts{routes: timeouts, fallback: fallback})
+}
+
+// MiddlewareDeadline bounds the request by the timeout of its route, or the
+// shorter one of the TimeoutHeader. The store calls are canceled along with
+// the request, once the client goes away or the deadline passes.
+func (c *EndpointHandler) MiddlewareDeadline(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		timeout := c.timeoutOf(request)
+
+		if header := request.Header.Get(TimeoutHeader); header != "" {
+			requested, err := parseTimeout(header)
+			if err != nil {
+				c.logger.InfoContext(request.Context(), errorParsingTimeout, "header", header, "error", err)
+				c.writeMessage(response, request, http.StatusBadRequest, fmt.Sprintf(errorTimeoutHeader, TimeoutHeader, header))
+				return
+			}
+			if timeout <= 0 || requested < timeout {
+				timeout = requested
+			}
+		}
+
+		if timeout <= 0 {
+			next.ServeHTTP(response, request)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(context.WithValue(request.Context(), keyTimeout{}, timeout), timeout)
+		defer cancel()
+
+		next.ServeHTTP(response, request.WithContext(ctx))
+	})
+}
+
+// timeoutOf returns the timeout of the route of request, 0 when there is
+// none.
+func (c *EndpointHandler) timeoutOf(request *http.Request) time.Duration {
+
+	timeouts := c.routeTimeouts.Load()
+
+	if route := mux.CurrentRoute(request); route != nil {
+		if timeout, ok := timeouts.routes[route.GetName()]; ok {
+			return timeout
+		}
+	}
+
+	return timeouts.fallback
+}
+
+// timedOut answers 504 when err comes from the deadline of the request, in
+// the API or in mongo, and then reports true.
+func (c *EndpointHandler) timedOut(response http.ResponseWriter, request *http.Request, err error) bool {
+
+	if !errors.Is(err, context.DeadlineExceeded) && !mongo.IsTimeout(err) {
+		return false
+	}
+
+	c.logger.WarnContext(request.Context(), requestTimedOut, "error", err)
+
+	detail := requestTimedOut
+	if timeout, ok := request.Context().Value(keyTimeout{}).(time.Duration); ok {
+		detail = fmt.Sprintf(errorDeadline, timeout)
+	}
+
+	c.writeProblem(response, request, http.StatusGatewayTimeout, detail)
+
+	return true
+}
+
+func (c *EndpointHandler) writeProblem(response http.ResponseWriter, request *http.Request, status int, detail string) {
+
+	response.Header().Set(setContentType, problemType)
+	response.WriteHeader(status)
+
+	err := json.NewEncoder(response).Encode(problem{
+		Type:   "about:blank",
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: detail,
+	})
+	if err != nil {
+		c.logger.ErrorContext(request.Context(), errorWrittingProblem, "error", err)
+	}
+}
+
+// parseTimeout reads a duration such as 1.5s or a number of seconds.
+func parseTimeout(value string) (time.Duration, error) {
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil {
+		seconds, parseErr := strconv.ParseFloat(value, 64)
+		if parseErr != nil {
+			return 0, err
+		}
+		if !(seconds > 0) {
+			return 0, errTimeoutNotPositive
+		}
+		timeout = time.Duration(math.MaxInt64)
+		if seconds < float64(math.MaxInt64)/float64(time.Second) {
+			timeout = time.Duration(seconds * float64(time.Second))
+		}
+	}
+
+	if timeout <= 0 {
+		return 0, errTimeoutNotPositive
+	}
+
+	return timeout, nil
+}
@@ -3,28 +3,31 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
-	"log"
+	"log/slog"
+	"mime"
 	"net/http"
-	"os"
-	"regexp"
-	"strings"
-	"time"
+	"sync/atomic"
 
 	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
 
 	"github.com/gorilla/mux"
-	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
 type (
 	EndpointHandler struct {
-		logger     *log.Logger
-		collection *mongo.Collection
-		timeout    time.Duration
+		logger *slog.Logger
+		store  store.PersonStore
+		// routeTimeouts can be changed while serving
+		routeTimeouts  atomic.Pointer[routeTimeouts]
+		version        APIVersion
+		maxBodySize    int64
+		strictDecoding bool
+		nameEndpoint   string
 	}
 
 	keyProduct struct{}
@@ -36,32 +39,35 @@ const (
 	setContentType              = "content-type"
 	jsonType                    = "application/json"
 	internalError               = "Internal Error"
-	errorInserting              = "Error while inserting the person: %v \n%v\n"
-	errorMarshalling            = "Error while marshalling the result: %v \n%v\n"
-	errorExausting              = "Error while exausting the request body: %v\n"
-	errorQuerying               = "Error while querying the collection: %v \n%v\n"
-	errorWrittingResponse       = "Error while writing the error response: %v\n"
-	errorretrievingFromCursor   = "Error retrieving results from cursor: %v\n"
-	errorClosingCursor          = "Error closing cursor: %v\n"
-	errorFindingAllDocuments    = "Error while finding all documents: %v"
-	errorParsingID              = "Error while parsing the id: %v \n%v\n"
-	errorDeletingDocument       = "Error while deleting a document: %v \n%v\n"
-	errorFindingDocument        = "Error while finding a document: %v \n%v\n"
-	errorWrittingClientResponse = "Error while writing the client response: %v\n"
-	errorMarshallingPerson      = "Error marshalling a Person: %v"
-	errorUpdatingPerson         = "Error updating a Person: %v\n%v\n"
-	errorWrittingUpdate         = "Error while writing the no update operation response: %v\n"
+	errorInserting              = "Error while inserting the person"
+	errorMarshalling            = "Error while marshalling the result"
+	errorExausting              = "Error while exausting the request body"
+	errorQuerying               = "Error while querying the collection"
+	errorWrittingResponse       = "Error while writing the error response"
+	errorFindingAllDocuments    = "Error while finding all documents"
+	errorParsingID              = "Error while parsing the id"
+	errorDeletingDocument       = "Error while deleting a document"
+	errorFindingDocument        = "Error while finding a document"
+	errorWrittingClientResponse = "Error while writing the client response"
+	errorUpdatingPerson         = "Error updating a Person"
+	errorWrittingUpdate         = "Error while writing the no update operation response"
+	errorWrittingUpdated        = "Error while writing the update response"
+	invalidPerson               = "Invalid person"
 	errorValidatingPerson       = "Error validating person: %v"
-	errorMarshallingBody        = "Error while marshalling the request body: %v\n"
-	noPersonFound               = "No Person was found with the name: %v"
-	noIDFound                   = "No Person was found with the id: %v"
-	noUpdateOperation           = "No update operation was done to document with id: %v"
+	errorMarshallingBody        = "Error while decoding the request body"
+	errorParsingQuery           = "Error while parsing the query parameters"
+	errorBodyTooLarge           = "The request body is larger than %d bytes"
+	errorMalformedBody          = "Malformed request body: %v"
+	errorContentType            = "Unsupported content type %q, expected %s"
+	noPersonFound               = "No Person was found with the name"
+	noIDFound                   = "No Person was found with the id"
+	noUpdateOperation           = "No update operation was done to document"
 )
 
-func exaustRequestBody(r io.ReadCloser, log *log.Logger) {
-	_, copyErr := io.Copy(io.Discard, r)
+func exaustRequestBody(request *http.Request, logger *slog.Logger) {
+	_, copyErr := io.Copy(io.Discard, request.Body)
 	if copyErr != nil {
-		log.Printf(errorExausting, copyErr)
+		logger.WarnContext(request.Context(), errorExausting, "error", copyErr)
 	}
 }
 
@@ -75,30 +81,33 @@ func (c *EndpointHandler) CreatePersonEndpoint(response http.ResponseWriter, req
 
 	person := request.Context().Value(keyProduct{}).(data.Person)
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-	defer cancel()
-	result, err := c.collection.InsertOne(ctx, person)
+	id, err := c.store.Create(request.Context(), person)
+
+	if c.timedOut(response, request, err) {
+		exaustRequestBody(request, c.logger)
+		return
+	}
 
 	if err != nil {
 
 		http.Error(response, internalError, http.StatusInternalServerError)
-		c.logger.Printf(errorInserting, person, err)
+		c.logger.ErrorContext(request.Context(), errorInserting, "person", person, "error", err)
 		// exaust the request body
-		exaustRequestBody(request.Body, c.logger)
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
-	err = json.NewEncoder(response).Encode(result)
+	err = c.writeCreated(response, id, person)
 
 	if err != nil {
 
 		http.Error(response, internalError, http.StatusInternalServerError)
-		c.logger.Printf(errorMarshalling, result, err)
-		exaustRequestBody(request.Body, c.logger)
+		c.logger.ErrorContext(request.Context(), errorMarshalling, "id", id, "error", err)
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
-	exaustRequestBody(request.Body, c.logger)
+	exaustRequestBody(request, c.logger)
 
 }
 
@@ -110,73 +119,44 @@ func (c *EndpointHandler) GetPersonByNameEndpoint(response http.ResponseWriter,
 
 	response.Header().Set(setContentType, jsonType)
 
-	const NAME_ENDPOINT = "NAME_ENDPOINT"
-	name := mux.Vars(request)[os.Getenv(NAME_ENDPOINT)]
-
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-
-	defer cancel()
-
-	const regexPattern = `(?:\A|\s)(%v)(?:\s|\z)`
-	pattern := fmt.Sprintf(regexPattern, name)
+	name := mux.Vars(request)[c.nameEndpoint]
 
-	const regexOptions = "i"
-	regexValue := primitive.Regex{Pattern: pattern, Options: regexOptions}
-
-	const (
-		bsonKey  = "firstname"
-		regexKey = "$regex"
-	)
-	cursor, err := c.collection.Find(ctx, bson.D{primitive.E{Key: bsonKey, Value: bson.D{primitive.E{Key: regexKey, Value: regexValue}}}})
-
-	defer func() {
-		if err := cursor.Close(ctx); err != nil {
-			c.logger.Printf(errorClosingCursor, err)
-		}
-	}()
-
-	if err != nil {
-		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorQuerying, name, err)
-		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
-		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
-		}
+	people, err := c.store.FindByName(request.Context(), name)
 
+	if c.timedOut(response, request, err) {
 		return
 	}
 
-	var people data.People
-
-	people, err = appendPersonFromCursor(cursor, people, ctx, response, c.logger)
 	if err != nil {
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorretrievingFromCursor, err)
+		c.logger.ErrorContext(request.Context(), errorQuerying, "name", name, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
+
 		return
 	}
 
-	err = people.ToJSON(response)
-
-	if err == data.ErrNotFound {
-		c.logger.Printf(noPersonFound, name)
+	if len(people) == 0 {
+		c.logger.InfoContext(request.Context(), noPersonFound, "name", name)
+		c.notFound(response)
 		_, err := response.Write([]byte(`{ "message": "No Person was found with the name: '` + name + `'" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
 		return
 	}
 
+	err = c.writePeople(response, people, primitive.NilObjectID)
+
 	if err != nil {
 
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorMarshalling, people, err)
+		c.logger.ErrorContext(request.Context(), errorMarshalling, "result", people, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
 		return
 
@@ -198,53 +178,49 @@ func (c *EndpointHandler) GetPersonByIdEndpoint(response http.ResponseWriter, re
 
 	if errId != nil {
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorParsingID, paramsId, errId)
+		c.logger.InfoContext(request.Context(), errorParsingID, "id", paramsId, "error", errId)
 		_, err := response.Write([]byte(`{ "message": "` + errId.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
 		return
 	}
 
-	var person data.Person
-
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	person, err := c.store.GetByID(request.Context(), id)
 
-	defer cancel()
-
-	const bsonKey = "_id"
-	err := c.collection.FindOne(ctx, bson.D{{
-		Key: bsonKey, Value: id,
-	}}).Decode(&person)
+	if c.timedOut(response, request, err) {
+		return
+	}
 
-	if err == mongo.ErrNoDocuments {
-		c.logger.Printf(noIDFound, paramsId)
+	if err == data.ErrNotFound {
+		c.logger.InfoContext(request.Context(), noIDFound, "id", paramsId)
+		c.notFound(response)
 		_, err := response.Write([]byte(`{ "message": "No Person was found with the id: ` + paramsId + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
 		return
 	}
 
 	if err != nil {
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorFindingDocument, paramsId, err)
+		c.logger.ErrorContext(request.Context(), errorFindingDocument, "id", paramsId, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
 		return
 	}
 
-	err = person.ToJSON(response)
+	err = c.writePerson(response, person)
 
 	if err != nil {
 
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorMarshalling, person, err)
+		c.logger.ErrorContext(request.Context(), errorMarshalling, "result", person, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
 		return
 
@@ -265,50 +241,49 @@ func (c *EndpointHandler) DeletePersonByIdEndpoint(response http.ResponseWriter,
 
 	if err != nil {
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorParsingID, paramsId, err)
+		c.logger.InfoContext(request.Context(), errorParsingID, "id", paramsId, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
-		exaustRequestBody(request.Body, c.logger)
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
-	// var person data.Person
-
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	deletedCount, err := c.store.Delete(request.Context(), id)
 
-	defer cancel()
-
-	const bsonKey = "_id"
-	deleteResult, err := c.collection.DeleteOne(ctx, bson.D{{Key: bsonKey, Value: id}})
+	if c.timedOut(response, request, err) {
+		exaustRequestBody(request, c.logger)
+		return
+	}
 
 	if err != nil {
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorDeletingDocument, paramsId, err)
+		c.logger.ErrorContext(request.Context(), errorDeletingDocument, "id", paramsId, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
-		exaustRequestBody(request.Body, c.logger)
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
-	if deleteResult.DeletedCount == 0 {
-		c.logger.Printf(noIDFound, paramsId)
+	if deletedCount == 0 {
+		c.logger.InfoContext(request.Context(), noIDFound, "id", paramsId)
+		c.notFound(response)
 		_, err := response.Write([]byte(`{ "message": "No Person was found with the id: ` + paramsId + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
-		exaustRequestBody(request.Body, c.logger)
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
 	_, err = response.Write([]byte(`{ "message": "Person with id: ` + paramsId + ` was deleted" }`))
 	if err != nil {
-		c.logger.Printf(errorWrittingClientResponse, err)
+		c.logger.ErrorContext(request.Context(), errorWrittingClientResponse, "error", err)
 	}
-	exaustRequestBody(request.Body, c.logger)
+	exaustRequestBody(request, c.logger)
 
 }
 
@@ -325,100 +300,49 @@ func (c *EndpointHandler) UpdatePersonByIdEndpoint(response http.ResponseWriter,
 
 	if err != nil {
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorParsingID, paramsId, err)
+		c.logger.InfoContext(request.Context(), errorParsingID, "id", paramsId, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
-		}
-		_, copyErr := io.Copy(io.Discard, request.Body)
-		if copyErr != nil {
-			c.logger.Printf(errorExausting, copyErr)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
-	// var person data.PersonUpdate
-
 	person := request.Context().Value(keyProduct{}).(data.PersonUpdate)
 
-	personJson, err := json.Marshal(person)
-	// c.logger.Printf("%s\n", personJson)
+	modifiedCount, err := c.store.Update(request.Context(), id, person)
 
-	if err != nil {
-		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorMarshallingPerson, err)
-		_, err := response.Write([]byte(`{ "message": "Error processing the request" }`))
-		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
-		}
-		_, copyErr := io.Copy(io.Discard, request.Body)
-		if copyErr != nil {
-			c.logger.Printf(errorExausting, copyErr)
-		}
+	if c.timedOut(response, request, err) {
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-
-	defer cancel()
-
-	const regexPattern = `[{}"]`
-	reSquareBrackets := regexp.MustCompile(regexPattern)
-	personRegex := reSquareBrackets.ReplaceAllString(string(personJson), "")
-	reComma := regexp.MustCompile(`,`)
-	const colon = ":"
-	personRegex = reComma.ReplaceAllString(personRegex, colon)
-	keyValueSliceToUpdate := strings.Split(personRegex, colon)
-	// c.logger.Println(keyValueSliceToUpdate[0], keyValueSliceToUpdate[1])
-
-	lenKeystoUpdate := len(keyValueSliceToUpdate)
-
-	collection := c.collection
-	var updateResultsSum int64
-	const bsonCommand = "$set"
-	for i := 0; i < lenKeystoUpdate; i = i + 2 {
-		updateResult, err := collection.UpdateByID(
-			ctx,
-			id,
-			bson.D{
-				{Key: bsonCommand, Value: bson.D{{Key: keyValueSliceToUpdate[i], Value: keyValueSliceToUpdate[i+1]}}},
-			},
-		)
-
+	if err != nil {
+		response.WriteHeader(http.StatusInternalServerError)
+		c.logger.ErrorContext(request.Context(), errorUpdatingPerson, "id", paramsId, "error", err)
+		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			response.WriteHeader(http.StatusInternalServerError)
-			c.logger.Printf(errorUpdatingPerson, paramsId, err)
-			_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
-			if err != nil {
-				c.logger.Printf(errorWrittingResponse, err)
-			}
-			return
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
-		updateResultsSum += updateResult.ModifiedCount
-
+		return
 	}
 
-	if updateResultsSum == 0 {
-		c.logger.Printf(noUpdateOperation, paramsId)
+	if modifiedCount == 0 {
+		c.logger.InfoContext(request.Context(), noUpdateOperation, "id", paramsId)
 		_, err := response.Write([]byte(`{ "message": "No update operation was done to document with id: ` + paramsId + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingUpdate, err)
-		}
-		_, copyErr := io.Copy(io.Discard, request.Body)
-		if copyErr != nil {
-			c.logger.Printf(errorExausting, copyErr)
+			c.logger.ErrorContext(request.Context(), errorWrittingUpdate, "error", err)
 		}
+		exaustRequestBody(request, c.logger)
 		return
 	}
 
 	_, err = response.Write([]byte(`{ "message": "Person with id: ` + paramsId + ` was updated" }`))
 	if err != nil {
-		c.logger.Printf("Error while writing the update response: %v\n", err)
-	}
-	_, copyErr := io.Copy(io.Discard, request.Body)
-	if copyErr != nil {
-		c.logger.Printf(errorExausting, copyErr)
+		c.logger.ErrorContext(request.Context(), errorWrittingUpdated, "error", err)
 	}
+	exaustRequestBody(request, c.logger)
 
 }
 
@@ -430,51 +354,49 @@ func (c *EndpointHandler) GetPeopleEndpoint(response http.ResponseWriter, reques
 
 	response.Header().Set(setContentType, jsonType)
 
-	var people data.People
-
-	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
-
-	defer cancel()
-
-	cursor, err := c.collection.Find(ctx, bson.M{})
-
-	defer func() {
-		if err := cursor.Close(ctx); err != nil {
-			c.logger.Printf(errorClosingCursor, err)
-		}
-	}()
+	opts, err := listOptionsFromQuery(request.URL.Query())
 
 	if err != nil {
-		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorFindingAllDocuments, err)
+		response.WriteHeader(http.StatusBadRequest)
+		c.logger.InfoContext(request.Context(), errorParsingQuery, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
+		return
+	}
 
+	people, err := c.store.List(request.Context(), opts)
+
+	if c.timedOut(response, request, err) {
 		return
 	}
 
-	people, err = appendPersonFromCursor(cursor, people, ctx, response, c.logger)
 	if err != nil {
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf("Error while appending people from cursor: %v", err)
+		c.logger.ErrorContext(request.Context(), errorFindingAllDocuments, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
+
 		return
 	}
 
-	err = people.ToJSON(response)
+	var next primitive.ObjectID
+	if opts.Limit > 0 && int64(len(people)) == opts.Limit {
+		next = people[len(people)-1].ID
+	}
+
+	err = c.writePeople(response, people, next)
 
 	if err != nil {
 
 		response.WriteHeader(http.StatusInternalServerError)
-		c.logger.Printf(errorMarshalling, people, err)
+		c.logger.ErrorContext(request.Context(), errorMarshalling, "result", people, "error", err)
 		_, err := response.Write([]byte(`{ "message": "` + err.Error() + `" }`))
 		if err != nil {
-			c.logger.Printf(errorWrittingResponse, err)
+			c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
 		}
 		return
 
@@ -482,13 +404,17 @@ func (c *EndpointHandler) GetPeopleEndpoint(response http.ResponseWriter, reques
 
 }
 
-func NewEndpointHandler(logger *log.Logger, collection *mongo.Collection, opts ...option) *EndpointHandler {
+func NewEndpointHandler(logger *slog.Logger, store store.PersonStore, opts ...option) *EndpointHandler {
 	handler := &EndpointHandler{
-		logger:     logger,
-		collection: collection,
-		timeout:    5 * time.Second,
+		logger:       logger,
+		store:        store,
+		version:      V1,
+		maxBodySize:  1 << 20,
+		nameEndpoint: "name",
 	}
 
+	handler.SetRouteTimeouts(nil, 0)
+
 	for i := range opts {
 		opts[i](handler)
 
@@ -498,34 +424,27 @@ func NewEndpointHandler(logger *log.Logger, collection *mongo.Collection, opts .
 
 }
 
-func appendPersonFromCursor(cursor *mongo.Cursor, people data.People, ctx context.Context, response http.ResponseWriter, logger *log.Logger) (data.People, error) {
-
-	// stop := timer.StartTimer("appendPersonFromCursor", logger)
-
-	// defer stop()
-
-	for cursor.Next(ctx) {
-
-		var person data.Person
-		err := cursor.Decode(&person)
-		if err != nil {
-			logger.Printf("Error decoding person: %v", err)
-			continue
-		}
-		people = append(people, &person)
+// WithNameEndpoint sets the path variable holding the name of the people
+// searched by name.
+func WithNameEndpoint(name string) option {
+	return func(handler *EndpointHandler) {
+		handler.nameEndpoint = name
 	}
-	if err := cursor.Err(); err != nil {
+}
 
-		return nil, err
+// WithMaxBodySize bounds the size of the request bodies, larger ones are
+// rejected with 413.
+func WithMaxBodySize(size int64) option {
+	return func(handler *EndpointHandler) {
+		handler.maxBodySize = size
 	}
-
-	return people, nil
-
 }
 
-func WithTimeout(timeout time.Duration) option {
+// WithStrictDecoding rejects the request bodies with fields the API version
+// does not know.
+func WithStrictDecoding(strict bool) option {
 	return func(handler *EndpointHandler) {
-		handler.timeout = timeout
+		handler.strictDecoding = strict
 	}
 }
 
@@ -533,16 +452,16 @@ func (c *EndpointHandler) MiddlewareValidateProduct(next http.Handler) http.Hand
 	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
 		var person data.Person
 
-		err := person.FromJSON(request.Body)
-
-		if err != nil {
-			response.WriteHeader(http.StatusInternalServerError)
-			c.logger.Printf(errorMarshallingBody, err)
+		ok := c.decodeBody(response, request, func(r io.Reader) (err error) {
+			person, err = c.decodePerson(r)
+			return err
+		})
+		if !ok {
 			return
 		}
 
 		if err := person.Validate(); err != nil {
-			c.logger.Printf(errorValidatingPerson, err)
+			c.logger.InfoContext(request.Context(), invalidPerson, "error", err)
 			http.Error(response, fmt.Sprintf(errorValidatingPerson, err), http.StatusBadRequest)
 			return
 		}
@@ -560,16 +479,16 @@ func (c *EndpointHandler) MiddlewareValidateUpdateRequest(next http.Handler) htt
 	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
 		var person data.PersonUpdate
 
-		err := person.FromJSON(request.Body)
-
-		if err != nil {
-			response.WriteHeader(http.StatusInternalServerError)
-			c.logger.Printf(errorMarshallingBody, err)
+		ok := c.decodeBody(response, request, func(r io.Reader) (err error) {
+			person, err = c.decodePersonUpdate(r)
+			return err
+		})
+		if !ok {
 			return
 		}
 
 		if err := person.Validate(); err != nil {
-			c.logger.Printf(errorValidatingPerson, err)
+			c.logger.InfoContext(request.Context(), invalidPerson, "error", err)
 			http.Error(response, fmt.Sprintf(errorValidatingPerson, err), http.StatusBadRequest)
 			return
 		}
@@ -582,3 +501,45 @@ func (c *EndpointHandler) MiddlewareValidateUpdateRequest(next http.Handler) htt
 		next.ServeHTTP(response, request)
 	})
 }
+
+// decodeBody decodes the body of request with decode, bounded by the maximum
+// body size. It answers 415 to bodies that are not JSON, 413 to the ones too
+// large and 400 to the malformed ones, and then reports false.
+func (c *EndpointHandler) decodeBody(response http.ResponseWriter, request *http.Request, decode func(io.Reader) error) bool {
+
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get(setContentType))
+	if err != nil || mediaType != jsonType {
+		c.writeMessage(response, request, http.StatusUnsupportedMediaType, fmt.Sprintf(errorContentType, request.Header.Get(setContentType), jsonType))
+		return false
+	}
+
+	err = decode(http.MaxBytesReader(response, request.Body, c.maxBodySize))
+	if err == nil {
+		return true
+	}
+
+	c.logger.InfoContext(request.Context(), errorMarshallingBody, "error", err)
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		c.writeMessage(response, request, http.StatusRequestEntityTooLarge, fmt.Sprintf(errorBodyTooLarge, tooLarge.Limit))
+		return false
+	}
+
+	c.writeMessage(response, request, http.StatusBadRequest, fmt.Sprintf(errorMalformedBody, err))
+
+	return false
+}
+
+func (c *EndpointHandler) writeMessage(response http.ResponseWriter, request *http.Request, status int, message string) {
+
+	response.Header().Set(setContentType, jsonType)
+	response.WriteHeader(status)
+
+	err := json.NewEncoder(response).Encode(struct {
+		Message string `json:"message"`
+	}{message})
+	if err != nil {
+		c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+	}
+}
@@ -0,0 +1,116 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// slowStore answers once the context of the call is done.
+type slowStore struct {
+	store.PersonStore
+}
+
+func (slowStore) GetByID(ctx context.Context, id primitive.ObjectID) (*data.Person, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestMiddlewareValidateProduct(t *testing.T) {
+
+	handler := NewEndpointHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), nil, WithAPIVersion(V2), WithMaxBodySize(64), WithStrictDecoding(true))
+
+	validated := handler.MiddlewareValidateProduct(http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+		response.WriteHeader(http.StatusCreated)
+	}))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		status      int
+	}{
+		{"valid", "application/json; charset=utf-8", `{"firstName": "David", "lastName": "Hernandez"}`, http.StatusCreated},
+		{"missing content type", "", `{"firstName": "David", "lastName": "Hernandez"}`, http.StatusUnsupportedMediaType},
+		{"form", "application/x-www-form-urlencoded", `firstName=David`, http.StatusUnsupportedMediaType},
+		{"malformed", jsonType, `{"firstName": "David"`, http.StatusBadRequest},
+		{"unknown field", jsonType, `{"firstName": "David", "lastName": "Hernandez", "age": 3}`, http.StatusBadRequest},
+		{"trailing data", jsonType, `{"firstName": "David", "lastName": "Hernandez"} {}`, http.StatusBadRequest},
+		{"too large", jsonType, `{"firstName": "` + strings.Repeat("a", 64) + `"}`, http.StatusRequestEntityTooLarge},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodPost, "/v2/person", strings.NewReader(test.body))
+			if test.contentType != "" {
+				request.Header.Set(setContentType, test.contentType)
+			}
+
+			response := httptest.NewRecorder()
+			validated.ServeHTTP(response, request)
+
+			if response.Code != test.status {
+				t.Fatalf("expected %d, got %d: %s", test.status, response.Code, response.Body)
+			}
+		})
+	}
+}
+
+func TestMiddlewareDeadline(t *testing.T) {
+
+	handler := NewEndpointHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), slowStore{}, WithRouteTimeouts(map[string]time.Duration{"getPerson": time.Hour}, time.Hour))
+
+	router := mux.NewRouter()
+	router.Use(handler.MiddlewareDeadline)
+	router.HandleFunc("/person/{id}", handler.GetPersonByIdEndpoint).Name("getPerson")
+
+	tests := []struct {
+		name    string
+		timeout string
+		status  int
+		detail  string
+	}{
+		{"shortened by the client", "50ms", http.StatusGatewayTimeout, "The request did not complete within 50ms"},
+		{"seconds", "0.05", http.StatusGatewayTimeout, "The request did not complete within 50ms"},
+		{"invalid", "soon", http.StatusBadRequest, ""},
+		{"negative", "-1s", http.StatusBadRequest, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/person/"+primitive.NewObjectID().Hex(), nil)
+			request.Header.Set(TimeoutHeader, test.timeout)
+
+			response := httptest.NewRecorder()
+			router.ServeHTTP(response, request)
+
+			if response.Code != test.status {
+				t.Fatalf("expected %d, got %d: %s", test.status, response.Code, response.Body)
+			}
+
+			if test.detail == "" {
+				return
+			}
+
+			var body problem
+			if err := json.NewDecoder(response.Body).Decode(&body); err != nil {
+				t.Fatal(err)
+			}
+			if response.Header().Get(setContentType) != problemType || body.Status != test.status || body.Detail != test.detail {
+				t.Errorf("expected a problem with detail %q, got %q %+v", test.detail, response.Header().Get(setContentType), body)
+			}
+		})
+	}
+}
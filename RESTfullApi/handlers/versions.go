@@ -0,0 +1,165 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type APIVersion string
+
+const (
+	V1 APIVersion = "v1"
+	V2 APIVersion = "v2"
+)
+
+const (
+	deprecationHeader = "Deprecation"
+	sunsetHeader      = "Sunset"
+	linkHeader        = "Link"
+
+	maxPageSize = 1000
+)
+
+func WithAPIVersion(version APIVersion) option {
+	return func(handler *EndpointHandler) {
+		handler.version = version
+	}
+}
+
+// PathPrefix returns the prefix the routes of the version are mounted on.
+func (v APIVersion) PathPrefix() string {
+	return "/" + string(v)
+}
+
+// writePerson encodes person using the DTO of the handler's API version.
+func (c *EndpointHandler) writePerson(w io.Writer, person *data.Person) error {
+
+	if c.version == V2 {
+		return data.NewPersonV2(person).ToJSON(w)
+	}
+
+	return data.NewPersonV1(person).ToJSON(w)
+}
+
+// writePeople encodes people using the DTO of the handler's API version. On
+// /v2 next, when set, is returned as the cursor of the following page while
+// /v1 returns data.ErrNotFound when there is nobody to encode.
+func (c *EndpointHandler) writePeople(w io.Writer, people data.People, next primitive.ObjectID) error {
+
+	if c.version == V2 {
+		dto := data.NewPeopleV2(people)
+		if !next.IsZero() {
+			dto.Next = next.Hex()
+		}
+		return dto.ToJSON(w)
+	}
+
+	return data.NewPeopleV1(people).ToJSON(w)
+}
+
+// notFound sets the status of a not found response, /v1 answers those with
+// 200 and a message.
+func (c *EndpointHandler) notFound(response http.ResponseWriter) {
+
+	if c.version != V1 {
+		response.WriteHeader(http.StatusNotFound)
+	}
+}
+
+// listOptionsFromQuery reads the optional limit and after paging parameters.
+func listOptionsFromQuery(query url.Values) (store.ListOptions, error) {
+
+	var opts store.ListOptions
+
+	if limit := query.Get("limit"); limit != "" {
+		n, err := strconv.ParseInt(limit, 10, 64)
+		if err != nil || n <= 0 || n > maxPageSize {
+			return opts, fmt.Errorf("limit must be between 1 and %d", maxPageSize)
+		}
+		opts.Limit = n
+	}
+
+	if after := query.Get("after"); after != "" {
+		id, err := primitive.ObjectIDFromHex(after)
+		if err != nil {
+			return opts, fmt.Errorf("invalid after cursor: %w", err)
+		}
+		opts.After = id
+	}
+
+	return opts, nil
+}
+
+// writeCreated encodes the response of a successful insert.
+func (c *EndpointHandler) writeCreated(response http.ResponseWriter, id primitive.ObjectID, person data.Person) error {
+
+	if c.version == V2 {
+		person.ID = id
+		response.Header().Set("Location", fmt.Sprintf("%s/person/%s", V2.PathPrefix(), id.Hex()))
+		response.WriteHeader(http.StatusCreated)
+		return data.NewPersonV2(&person).ToJSON(response)
+	}
+
+	return json.NewEncoder(response).Encode(mongo.InsertOneResult{InsertedID: id})
+}
+
+func (c *EndpointHandler) decodePerson(r io.Reader) (data.Person, error) {
+
+	if c.version == V2 {
+		var person data.PersonV2
+		if err := data.DecodeJSON(r, &person, c.strictDecoding); err != nil {
+			return data.Person{}, err
+		}
+		return person.ToPerson()
+	}
+
+	var person data.PersonV1
+	if err := data.DecodeJSON(r, &person, c.strictDecoding); err != nil {
+		return data.Person{}, err
+	}
+
+	return person.ToPerson(), nil
+}
+
+func (c *EndpointHandler) decodePersonUpdate(r io.Reader) (data.PersonUpdate, error) {
+
+	if c.version == V2 {
+		var person data.PersonUpdateV2
+		err := data.DecodeJSON(r, &person, c.strictDecoding)
+		return person.ToPersonUpdate(), err
+	}
+
+	var person data.PersonUpdateV1
+	err := data.DecodeJSON(r, &person, c.strictDecoding)
+
+	return person.ToPersonUpdate(), err
+}
+
+// MiddlewareDeprecation flags every response of an old API version as
+// deprecated, pointing clients at its successor. The Sunset header is only
+// sent when sunset is not zero.
+func MiddlewareDeprecation(successor APIVersion, sunset time.Time) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			response.Header().Set(deprecationHeader, "true")
+			response.Header().Set(linkHeader, fmt.Sprintf(`<%s>; rel="successor-version"`, successor.PathPrefix()))
+			if !sunset.IsZero() {
+				response.Header().Set(sunsetHeader, sunset.UTC().Format(http.TimeFormat))
+			}
+
+			next.ServeHTTP(response, request)
+		})
+	}
+}
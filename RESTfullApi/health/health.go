@@ -0,0 +1,185 @@
+// Package health serves the liveness and readiness probes of the service.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// Check reports an unhealthy dependency with an error, it must return
+	// once ctx is done.
+	Check func(ctx context.Context) error
+
+	// Checker runs the readiness checks of the service, which also fails as
+	// soon as the service starts shutting down.
+	Checker struct {
+		logger       *slog.Logger
+		timeout      time.Duration
+		mu           sync.Mutex
+		checks       []namedCheck
+		shuttingDown atomic.Bool
+	}
+
+	namedCheck struct {
+		name  string
+		check Check
+	}
+
+	// Result is the outcome of a check, Latency is how long it took.
+	Result struct {
+		Name    string `json:"name"`
+		Status  string `json:"status"`
+		Latency string `json:"latency"`
+		Error   string `json:"error,omitempty"`
+	}
+
+	// Report is the body of the probes.
+	Report struct {
+		Status string   `json:"status"`
+		Checks []Result `json:"checks,omitempty"`
+	}
+
+	option func(checker *Checker)
+)
+
+const (
+	StatusOK      = "ok"
+	StatusFailing = "failing"
+
+	// ShutdownCheck is the name of the check failing once shutdown started.
+	ShutdownCheck = "shutdown"
+
+	setContentType        = "content-type"
+	jsonType              = "application/json"
+	errorWrittingResponse = "Error while writing the health response"
+)
+
+var errShuttingDown = errors.New("the service is shutting down")
+
+// NewChecker returns a checker running each check for at most 2 seconds.
+func NewChecker(logger *slog.Logger, opts ...option) *Checker {
+
+	checker := &Checker{logger: logger, timeout: 2 * time.Second}
+
+	for i := range opts {
+		opts[i](checker)
+	}
+
+	return checker
+}
+
+func WithTimeout(timeout time.Duration) option {
+	return func(checker *Checker) {
+		checker.timeout = timeout
+	}
+}
+
+// Add adds a readiness check, the checks run concurrently in each probe.
+func (c *Checker) Add(name string, check Check) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checks = append(c.checks, namedCheck{name: name, check: check})
+}
+
+// SetShuttingDown makes the readiness fail from now on, so that the load
+// balancers stop sending requests while the in-flight ones are served.
+func (c *Checker) SetShuttingDown() {
+	c.shuttingDown.Store(true)
+}
+
+// Ready runs the checks and reports whether they all passed.
+func (c *Checker) Ready(ctx context.Context) (Report, bool) {
+
+	c.mu.Lock()
+	checks := append([]namedCheck{{name: ShutdownCheck, check: c.checkShutdown}}, c.checks...)
+	c.mu.Unlock()
+
+	results := make([]Result, len(checks))
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check namedCheck) {
+			defer wg.Done()
+			results[i] = c.run(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	report := Report{Status: StatusOK, Checks: results}
+	for _, result := range results {
+		if result.Status != StatusOK {
+			report.Status = StatusFailing
+		}
+	}
+
+	return report, report.Status == StatusOK
+}
+
+// LiveEndpoint answers 200 as long as the process serves requests, it checks
+// no dependency so that a failing database does not get the service
+// restarted.
+func (c *Checker) LiveEndpoint(response http.ResponseWriter, request *http.Request) {
+	c.write(response, request, http.StatusOK, Report{Status: StatusOK})
+}
+
+// ReadyEndpoint answers 200 when every check passed and 503 otherwise, with
+// the result of each check.
+func (c *Checker) ReadyEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	report, ok := c.Ready(request.Context())
+
+	status := http.StatusOK
+	if !ok {
+		status = http.StatusServiceUnavailable
+	}
+
+	c.write(response, request, status, report)
+}
+
+func (c *Checker) run(ctx context.Context, check namedCheck) Result {
+
+	ctx, cancel := context.WithTimeout(ctx, c.timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := check.check(ctx)
+	result := Result{Name: check.name, Status: StatusOK, Latency: time.Since(start).String()}
+
+	if err != nil {
+		result.Status = StatusFailing
+		result.Error = err.Error()
+	}
+
+	return result
+}
+
+func (c *Checker) checkShutdown(context.Context) error {
+
+	if c.shuttingDown.Load() {
+		return errShuttingDown
+	}
+
+	return nil
+}
+
+func (c *Checker) write(response http.ResponseWriter, request *http.Request, status int, report Report) {
+
+	response.Header().Set(setContentType, jsonType)
+	// probes must not be answered from a cache
+	response.Header().Set("Cache-Control", "no-store")
+	response.WriteHeader(status)
+
+	if err := json.NewEncoder(response).Encode(report); err != nil {
+		c.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+	}
+}
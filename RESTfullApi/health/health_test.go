@@ -0,0 +1,75 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestReadyEndpoint(t *testing.T) {
+
+	checker := NewChecker(slog.New(slog.NewTextHandler(io.Discard, nil)), WithTimeout(10*time.Millisecond))
+
+	var mongoErr error
+	checker.Add("mongo", func(context.Context) error { return mongoErr })
+	checker.Add("slow", func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	ready := func() (int, Report) {
+		recorder := httptest.NewRecorder()
+		checker.ReadyEndpoint(recorder, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+		var report Report
+		if err := json.NewDecoder(recorder.Body).Decode(&report); err != nil {
+			t.Fatal(err)
+		}
+		return recorder.Code, report
+	}
+
+	status := func(report Report) map[string]string {
+		statuses := map[string]string{}
+		for _, result := range report.Checks {
+			statuses[result.Name] = result.Status
+		}
+		return statuses
+	}
+
+	code, report := ready()
+	if code != http.StatusServiceUnavailable || report.Status != StatusFailing {
+		t.Fatalf("expected the slow check to time out, got %d %+v", code, report)
+	}
+	if statuses := status(report); statuses["mongo"] != StatusOK || statuses["slow"] != StatusFailing || statuses[ShutdownCheck] != StatusOK {
+		t.Fatalf("unexpected check results %v", statuses)
+	}
+
+	checker = NewChecker(slog.New(slog.NewTextHandler(io.Discard, nil)))
+	checker.Add("mongo", func(context.Context) error { return mongoErr })
+
+	if code, _ := ready(); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+
+	mongoErr = errors.New("server selection timeout")
+	if code, report := ready(); code != http.StatusServiceUnavailable || report.Checks[1].Error != mongoErr.Error() {
+		t.Fatalf("expected the mongo check to fail, got %d %+v", code, report)
+	}
+
+	mongoErr = nil
+	checker.SetShuttingDown()
+	if code, report := ready(); code != http.StatusServiceUnavailable || status(report)[ShutdownCheck] != StatusFailing {
+		t.Fatalf("expected the readiness to fail once shutting down, got %d %+v", code, report)
+	}
+
+	recorder := httptest.NewRecorder()
+	checker.LiveEndpoint(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if recorder.Code != http.StatusOK {
+		t.Fatalf("expected the liveness to pass while shutting down, got %d", recorder.Code)
+	}
+}
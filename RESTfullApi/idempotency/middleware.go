@@ -0,0 +1,310 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/tenant"
+)
+
+type (
+	// Handler stores the responses of the requests carrying an
+	// Idempotency-Key and replays them to the retries.
+	Handler struct {
+		logger      *slog.Logger
+		store       Store
+		ttl         time.Duration
+		lockTTL     time.Duration
+		wait        time.Duration
+		poll        time.Duration
+		timeout     time.Duration
+		maxBodySize int64
+		now         func() time.Time
+	}
+
+	// recorder copies the response written through it.
+	recorder struct {
+		http.ResponseWriter
+		status int
+		body   bytes.Buffer
+	}
+
+	option func(handler *Handler)
+)
+
+const (
+	KeyHeader      = "Idempotency-Key"
+	ReplayedHeader = "Idempotent-Replayed"
+
+	maxKeyLength = 255
+
+	setContentType        = "content-type"
+	jsonType              = "application/json"
+	retryAfterHeader      = "Retry-After"
+	errorStoringResponse  = "Error while storing the response of an idempotency key"
+	errorReleasingKey     = "Error while releasing an idempotency key"
+	errorBeginning        = "Error while looking up an idempotency key"
+	errorWrittingResponse = "Error while writing the idempotency response"
+	invalidKey            = "The Idempotency-Key header must be 1 to 255 characters"
+	bodyTooLarge          = "The request body is too large"
+	unreadableBody        = "The request body could not be read"
+	keyReused             = "The Idempotency-Key was used with another request"
+	keyInProgress         = "A request with this Idempotency-Key is in progress"
+	storeDown             = "Idempotency keys are unavailable"
+)
+
+// replayedHeaders are the response headers replayed along with the status and
+// body, the others are set again by the middlewares wrapping the handler.
+var replayedHeaders = []string{"Content-Type", "Location"}
+
+func NewHandler(logger *slog.Logger, store Store, opts ...option) *Handler {
+	handler := &Handler{
+		logger:      logger,
+		store:       store,
+		ttl:         24 * time.Hour,
+		lockTTL:     time.Minute,
+		wait:        5 * time.Second,
+		poll:        100 * time.Millisecond,
+		timeout:     5 * time.Second,
+		maxBodySize: 1 << 20,
+		now:         time.Now,
+	}
+
+	for i := range opts {
+		opts[i](handler)
+	}
+
+	return handler
+}
+
+// WithTTL sets how long a response is replayed.
+func WithTTL(ttl time.Duration) option {
+	return func(handler *Handler) {
+		handler.ttl = ttl
+	}
+}
+
+// WithLockTTL sets how long a request holds its key, a request outliving it
+// lets the retries run again.
+func WithLockTTL(ttl time.Duration) option {
+	return func(handler *Handler) {
+		handler.lockTTL = ttl
+	}
+}
+
+// WithWait sets how long a retry waits for the request holding its key before
+// it gets 409, zero answers 409 at once.
+func WithWait(wait time.Duration) option {
+	return func(handler *Handler) {
+		handler.wait = wait
+	}
+}
+
+// WithMaxBodySize bounds the request bodies read to fingerprint the requests.
+func WithMaxBodySize(size int64) option {
+	return func(handler *Handler) {
+		handler.maxBodySize = size
+	}
+}
+
+// Middleware serves the first request of an Idempotency-Key and replays its
+// response to the requests with the same key and body. Responses with a 5xx
+// status are not stored, the request runs again when retried. The keys are
+// scoped by caller and tenant, so it must run after the authentication and
+// tenant middlewares.
+func (h *Handler) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		idempotencyKey := request.Header.Get(KeyHeader)
+		if idempotencyKey == "" {
+			next.ServeHTTP(response, request)
+			return
+		}
+		if len(idempotencyKey) > maxKeyLength {
+			h.writeError(response, request, http.StatusBadRequest, invalidKey)
+			return
+		}
+
+		body, err := io.ReadAll(http.MaxBytesReader(response, request.Body, h.maxBodySize))
+		if err != nil {
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				h.writeError(response, request, http.StatusRequestEntityTooLarge, bodyTooLarge)
+				return
+			}
+			h.writeError(response, request, http.StatusBadRequest, unreadableBody)
+			return
+		}
+		request.Body = io.NopCloser(bytes.NewReader(body))
+
+		key := scopedKey(request.Context(), idempotencyKey)
+		fingerprint := fingerprint(request, body)
+		deadline := h.now().Add(h.wait)
+
+		for {
+			now := h.now()
+			record := &Record{Key: key, Fingerprint: fingerprint, CreatedAt: now, ExpiresAt: now.Add(h.lockTTL)}
+
+			existing, err := h.begin(request.Context(), record)
+			if err != nil && !errors.Is(err, errRecordGone) {
+				h.logger.ErrorContext(request.Context(), errorBeginning, "key", idempotencyKey, "error", err)
+				h.writeError(response, request, http.StatusServiceUnavailable, storeDown)
+				return
+			}
+
+			switch {
+			case err != nil:
+				// the record was released meanwhile, try again
+			case existing == nil:
+				h.serve(next, response, request, record)
+				return
+			case existing.Fingerprint != fingerprint:
+				h.writeError(response, request, http.StatusUnprocessableEntity, keyReused)
+				return
+			case existing.Done:
+				replay(response, existing)
+				return
+			}
+
+			if !h.now().Before(deadline) {
+				h.inProgress(response, request)
+				return
+			}
+
+			// the wait ends with the deadline of the request, only a client
+			// which went away gets no answer
+			select {
+			case <-request.Context().Done():
+				if errors.Is(request.Context().Err(), context.DeadlineExceeded) {
+					h.inProgress(response, request)
+				}
+				return
+			case <-time.After(h.poll):
+			}
+		}
+	})
+}
+
+// inProgress answers 409 to a request whose key is held by another one, the
+// client may retry it after Retry-After.
+func (h *Handler) inProgress(response http.ResponseWriter, request *http.Request) {
+
+	response.Header().Set(retryAfterHeader, "1")
+	h.writeError(response, request, http.StatusConflict, keyInProgress)
+}
+
+func (h *Handler) begin(ctx context.Context, record *Record) (*Record, error) {
+
+	ctx, cancel := h.storeContext(ctx)
+	defer cancel()
+
+	return h.store.Begin(ctx, record)
+}
+
+// serve runs the request holding the key and stores its response.
+func (h *Handler) serve(next http.Handler, response http.ResponseWriter, request *http.Request, record *Record) {
+
+	recorded := &recorder{ResponseWriter: response, status: http.StatusOK}
+
+	next.ServeHTTP(recorded, request)
+
+	ctx, cancel := h.storeContext(request.Context())
+	defer cancel()
+
+	if recorded.status >= http.StatusInternalServerError {
+		if err := h.store.Release(ctx, record.Key); err != nil {
+			h.logger.ErrorContext(request.Context(), errorReleasingKey, "key", request.Header.Get(KeyHeader), "error", err)
+		}
+		return
+	}
+
+	record.Done = true
+	record.Status = recorded.status
+	record.Body = recorded.body.Bytes()
+	record.ExpiresAt = h.now().Add(h.ttl)
+	record.Header = http.Header{}
+	for _, name := range replayedHeaders {
+		if values := response.Header().Values(name); len(values) > 0 {
+			record.Header[name] = values
+		}
+	}
+
+	if err := h.store.Complete(ctx, record); err != nil {
+		h.logger.ErrorContext(request.Context(), errorStoringResponse, "key", request.Header.Get(KeyHeader), "error", err)
+	}
+}
+
+// storeContext keeps the values of ctx but not its cancellation, a response
+// must be stored even when the client went away.
+func (h *Handler) storeContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), h.timeout)
+}
+
+func replay(response http.ResponseWriter, record *Record) {
+
+	for name, values := range record.Header {
+		response.Header()[name] = values
+	}
+	response.Header().Set(ReplayedHeader, "true")
+	response.WriteHeader(record.Status)
+	response.Write(record.Body)
+}
+
+func (h *Handler) writeError(response http.ResponseWriter, request *http.Request, status int, message string) {
+
+	response.Header().Set(setContentType, jsonType)
+	response.WriteHeader(status)
+
+	err := json.NewEncoder(response).Encode(struct {
+		Message string `json:"message"`
+	}{message})
+	if err != nil {
+		h.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+	}
+}
+
+// scopedKey prefixes the key with the caller and the tenant of the request so
+// that clients can't replay each other's responses.
+func scopedKey(ctx context.Context, key string) string {
+
+	caller := "anonymous"
+	if principal, ok := auth.PrincipalFromContext(ctx); ok {
+		caller = principal.Subject
+	}
+
+	tenantID := ""
+	if t, ok := tenant.FromContext(ctx); ok {
+		tenantID = t.ID
+	}
+
+	return strings.Join([]string{tenantID, caller, key}, "|")
+}
+
+func fingerprint(request *http.Request, body []byte) string {
+
+	hash := sha256.New()
+	hash.Write([]byte(request.Method + " " + request.URL.Path + "\n"))
+	hash.Write(body)
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,122 @@
+package idempotency
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func (s *memoryStore) Begin(_ context.Context, record *Record) (*Record, error) {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[record.Key]; ok && existing.ExpiresAt.After(record.CreatedAt) {
+		copied := *existing
+		return &copied, nil
+	}
+
+	copied := *record
+	s.records[record.Key] = &copied
+
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, record *Record) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	copied := *record
+	s.records[record.Key] = &copied
+
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string) error {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+
+	return nil
+}
+
+func TestMiddleware(t *testing.T) {
+
+	store := &memoryStore{records: map[string]*Record{}}
+	handler := NewHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), store, WithWait(0))
+
+	calls, status := 0, http.StatusCreated
+	created := handler.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Location", "/v2/person/1")
+		w.WriteHeader(status)
+		w.Write(body)
+	}))
+
+	send := func(key, body string) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodPost, "/v2/person", strings.NewReader(body))
+		request.Header.Set(KeyHeader, key)
+		response := httptest.NewRecorder()
+		created.ServeHTTP(response, request)
+		return response
+	}
+
+	first := send("a", `{"firstName": "David"}`)
+	if first.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("expected 201 after one call, got %d after %d", first.Code, calls)
+	}
+
+	retry := send("a", `{"firstName": "David"}`)
+	if retry.Code != http.StatusCreated || calls != 1 {
+		t.Fatalf("retry: expected a replayed 201, got %d after %d calls", retry.Code, calls)
+	}
+	if retry.Body.String() != first.Body.String() || retry.Header().Get("Location") != "/v2/person/1" {
+		t.Errorf("retry: unexpected replay %q %v", retry.Body, retry.Header())
+	}
+	if retry.Header().Get(ReplayedHeader) != "true" {
+		t.Errorf("retry: missing %s", ReplayedHeader)
+	}
+
+	if response := send("a", `{"firstName": "Maria"}`); response.Code != http.StatusUnprocessableEntity {
+		t.Errorf("other body: expected 422, got %d", response.Code)
+	}
+
+	store.records["|anonymous|pending"] = &Record{Key: "|anonymous|pending", Fingerprint: fingerprint(httptest.NewRequest(http.MethodPost, "/v2/person", nil), []byte("{}")), ExpiresAt: handler.now().Add(handler.lockTTL)}
+	if response := send("pending", `{}`); response.Code != http.StatusConflict {
+		t.Errorf("in progress: expected 409, got %d", response.Code)
+	}
+
+	// the deadline of the request ends the wait for the pending key
+	waiting := NewHandler(slog.New(slog.NewTextHandler(io.Discard, nil)), store).Middleware(created)
+	request := httptest.NewRequest(http.MethodPost, "/v2/person", strings.NewReader(`{}`))
+	request.Header.Set(KeyHeader, "pending")
+	ctx, cancel := context.WithTimeout(request.Context(), 50*time.Millisecond)
+	defer cancel()
+	response := httptest.NewRecorder()
+	waiting.ServeHTTP(response, request.WithContext(ctx))
+	if response.Code != http.StatusConflict || response.Header().Get(retryAfterHeader) != "1" {
+		t.Errorf("deadline while waiting: expected 409 with Retry-After, got %d %v", response.Code, response.Header())
+	}
+
+	status = http.StatusInternalServerError
+	send("b", `{}`)
+	status = http.StatusCreated
+	if response := send("b", `{}`); response.Code != http.StatusCreated || calls != 3 {
+		t.Errorf("after a failure: expected the request to run again, got %d after %d calls", response.Code, calls)
+	}
+}
@@ -0,0 +1,126 @@
+// Package idempotency replays the response of the first request carrying an
+// Idempotency-Key to the retries of that request.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// Record is the request made with a key and, once Done, its response.
+	Record struct {
+		Key         string      `bson:"_id"`
+		Fingerprint string      `bson:"fingerprint"`
+		Done        bool        `bson:"done"`
+		Status      int         `bson:"status,omitempty"`
+		Header      http.Header `bson:"header,omitempty"`
+		Body        []byte      `bson:"body,omitempty"`
+		CreatedAt   time.Time   `bson:"createdAt"`
+		ExpiresAt   time.Time   `bson:"expiresAt"`
+	}
+
+	// Store persists the records of the idempotency keys.
+	Store interface {
+		// Begin saves record unless a record of its key expiring after
+		// record.CreatedAt exists, which it returns instead.
+		Begin(ctx context.Context, record *Record) (*Record, error)
+		// Complete saves the response of the pending record.
+		Complete(ctx context.Context, record *Record) error
+		// Release drops the pending record of key so that a retry runs again.
+		Release(ctx context.Context, key string) error
+	}
+
+	MongoStore struct {
+		collection *mongo.Collection
+	}
+)
+
+const (
+	idKey          = "_id"
+	fingerprintKey = "fingerprint"
+	doneKey        = "done"
+	statusKey      = "status"
+	headerKey      = "header"
+	bodyKey        = "body"
+	expiresAtKey   = "expiresAt"
+	setCommand     = "$set"
+	lteCommand     = "$lte"
+)
+
+// errRecordGone is returned by Begin when the record it collided with is
+// gone before it could be read, a retry will most likely save its own.
+var errRecordGone = errors.New("the idempotency record was released concurrently")
+
+func NewMongoStore(collection *mongo.Collection) *MongoStore {
+	return &MongoStore{collection: collection}
+}
+
+// EnsureIndexes lets mongo delete the expired records.
+func (s *MongoStore) EnsureIndexes(ctx context.Context) error {
+
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: expiresAtKey, Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
+}
+
+// Begin replaces the expired record of the key, mongo only deletes them about
+// every minute. A live record makes the upsert fail on the unique _id index.
+func (s *MongoStore) Begin(ctx context.Context, record *Record) (*Record, error) {
+
+	_, err := s.collection.ReplaceOne(ctx,
+		bson.M{idKey: record.Key, expiresAtKey: bson.M{lteCommand: record.CreatedAt.UTC()}},
+		record,
+		options.Replace().SetUpsert(true),
+	)
+	if err == nil {
+		return nil, nil
+	}
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, err
+	}
+
+	var existing Record
+
+	err = s.collection.FindOne(ctx, bson.M{idKey: record.Key}).Decode(&existing)
+	if err == mongo.ErrNoDocuments {
+		return nil, errRecordGone
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &existing, nil
+}
+
+func (s *MongoStore) Complete(ctx context.Context, record *Record) error {
+
+	_, err := s.collection.UpdateOne(ctx,
+		bson.M{idKey: record.Key, fingerprintKey: record.Fingerprint, doneKey: false},
+		bson.M{setCommand: bson.M{
+			doneKey:      true,
+			statusKey:    record.Status,
+			headerKey:    record.Header,
+			bodyKey:      record.Body,
+			expiresAtKey: record.ExpiresAt.UTC(),
+		}},
+	)
+
+	return err
+}
+
+func (s *MongoStore) Release(ctx context.Context, key string) error {
+
+	_, err := s.collection.DeleteOne(ctx, bson.M{idKey: key, doneKey: false})
+
+	return err
+}
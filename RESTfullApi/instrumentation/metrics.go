@@ -0,0 +1,268 @@
+// Package instrumentation records the RED metrics of the HTTP API, into a
+// registry of its caller.
+package instrumentation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type (
+	// Metrics holds the HTTP metrics, registered by New.
+	Metrics struct {
+		requests        *prometheus.CounterVec
+		status          *prometheus.CounterVec
+		duration        *prometheus.HistogramVec
+		inFlight        prometheus.Gauge
+		requestSize     *prometheus.HistogramVec
+		responseSize    *prometheus.HistogramVec
+		metricsEndpoint string
+	}
+
+	settings struct {
+		durationBuckets []float64
+		sizeBuckets     []float64
+		metricsEndpoint string
+	}
+
+	option func(settings *settings)
+
+	responseWriter struct {
+		http.ResponseWriter
+		statusCode int
+		size       int64
+	}
+
+	// countingBody counts the bytes of the request body read by the handlers.
+	countingBody struct {
+		io.ReadCloser
+		size int64
+	}
+
+	// requestLabels holds the labels only known once a request went through
+	// the middlewares of its route, such as the API key it was authenticated
+	// with or its tenant.
+	requestLabels struct {
+		key    string
+		tenant string
+	}
+
+	keyRequestLabels struct{}
+)
+
+const (
+	// anonymous labels the requests made without an API key.
+	anonymous = "anonymous"
+	// noTenant labels the requests not resolved to a tenant.
+	noTenant = "none"
+	// unmatched labels the path of the requests matching no route.
+	unmatched = "unmatched"
+	unknown   = "unknown"
+)
+
+var versionSegment = regexp.MustCompile(`^v[0-9]+$`)
+
+// NewRegistry returns a registry holding the Go runtime and process
+// collectors.
+func NewRegistry() *prometheus.Registry {
+
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return registry
+}
+
+// New returns the HTTP metrics, registered with registerer.
+func New(registerer prometheus.Registerer, opts ...option) (*Metrics, error) {
+
+	s := settings{durationBuckets: prometheus.DefBuckets, sizeBuckets: prometheus.ExponentialBuckets(100, 10, 6)}
+	for i := range opts {
+		opts[i](&s)
+	}
+
+	m := &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "http_requests_total",
+			Help: "Number of get requests.",
+		}, []string{"path", "method", "version", "key", "tenant"}),
+		status: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "response_status",
+			Help: "Status of HTTP response",
+		}, []string{"status", "method", "version", "key", "tenant"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_time_seconds",
+			Help:    "Duration of HTTP requests.",
+			Buckets: s.durationBuckets,
+		}, []string{"path", "method", "version", "tenant"}),
+		inFlight: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "http_requests_in_flight",
+			Help: "Number of HTTP requests being served.",
+		}),
+		requestSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_request_size_bytes",
+			Help:    "Size of the HTTP request bodies read by the handlers.",
+			Buckets: s.sizeBuckets,
+		}, []string{"path", "method"}),
+		responseSize: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "http_response_size_bytes",
+			Help:    "Size of the HTTP response bodies.",
+			Buckets: s.sizeBuckets,
+		}, []string{"path", "method"}),
+		metricsEndpoint: s.metricsEndpoint,
+	}
+
+	for _, collector := range []prometheus.Collector{m.requests, m.status, m.duration, m.inFlight, m.requestSize, m.responseSize} {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+
+	return m, nil
+}
+
+// WithDurationBuckets sets the buckets of the request durations, in seconds.
+func WithDurationBuckets(buckets []float64) option {
+	return func(settings *settings) {
+		settings.durationBuckets = buckets
+	}
+}
+
+// WithSizeBuckets sets the buckets of the request and response sizes, in
+// bytes.
+func WithSizeBuckets(buckets []float64) option {
+	return func(settings *settings) {
+		settings.sizeBuckets = buckets
+	}
+}
+
+// WithMetricsEndpoint leaves out the route serving the metrics.
+func WithMetricsEndpoint(path string) option {
+	return func(settings *settings) {
+		settings.metricsEndpoint = path
+	}
+}
+
+// Middleware records the HTTP metrics of every route but the one serving
+// them, labeled with the route template.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+
+		route := mux.CurrentRoute(r)
+
+		path, err := route.GetPathTemplate()
+		if err != nil {
+			path = unknown
+		}
+
+		if path == m.metricsEndpoint {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		m.serve(w, r, path, next)
+	})
+}
+
+// serve serves r with next, recording its metrics under path.
+func (m *Metrics) serve(w http.ResponseWriter, r *http.Request, path string, next http.Handler) {
+
+	m.inFlight.Inc()
+	defer m.inFlight.Dec()
+
+	start := time.Now()
+	rw := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+	labels := &requestLabels{key: anonymous, tenant: noTenant}
+	r = r.WithContext(context.WithValue(r.Context(), keyRequestLabels{}, labels))
+
+	body := &countingBody{ReadCloser: r.Body}
+	r.Body = body
+
+	next.ServeHTTP(rw, r)
+
+	version := apiVersion(path)
+
+	m.status.WithLabelValues(strconv.Itoa(rw.statusCode), r.Method, version, labels.key, labels.tenant).Inc()
+	m.requests.WithLabelValues(path, r.Method, version, labels.key, labels.tenant).Inc()
+	m.requestSize.WithLabelValues(path, r.Method).Observe(float64(body.size))
+	m.responseSize.WithLabelValues(path, r.Method).Observe(float64(rw.size))
+
+	// the tenant is only known once the request was served
+	observeWithTrace(r.Context(), m.duration.WithLabelValues(path, r.Method, version, labels.tenant), time.Since(start).Seconds())
+}
+
+// SetKeyLabel sets the name of the API key request was authenticated with,
+// which labels the metrics recorded by Middleware.
+func SetKeyLabel(request *http.Request, key string) {
+
+	if labels, ok := request.Context().Value(keyRequestLabels{}).(*requestLabels); ok {
+		labels.key = key
+	}
+}
+
+// SetTenantLabel sets the tenant request was resolved to, which labels the
+// metrics recorded by Middleware.
+func SetTenantLabel(request *http.Request, tenant string) {
+
+	if labels, ok := request.Context().Value(keyRequestLabels{}).(*requestLabels); ok {
+		labels.tenant = tenant
+	}
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *responseWriter) Write(b []byte) (int, error) {
+
+	n, err := rw.ResponseWriter.Write(b)
+	rw.size += int64(n)
+
+	return n, err
+}
+
+func (b *countingBody) Read(p []byte) (int, error) {
+
+	n, err := b.ReadCloser.Read(p)
+	b.size += int64(n)
+
+	return n, err
+}
+
+// apiVersion extracts the API version from a route template such as
+// /v2/person/{id}, routes outside a versioned subrouter are labeled "none".
+func apiVersion(path string) string {
+	segment, _, _ := strings.Cut(strings.TrimPrefix(path, "/"), "/")
+	if versionSegment.MatchString(segment) {
+		return segment
+	}
+
+	return "none"
+}
+
+// observeWithTrace observes value with the id of the sampled trace of ctx as
+// exemplar, which links the histogram buckets to the traces.
+func observeWithTrace(ctx context.Context, observer prometheus.Observer, value float64) {
+
+	span := trace.SpanContextFromContext(ctx)
+	if exemplarObserver, ok := observer.(prometheus.ExemplarObserver); ok && span.IsSampled() {
+		exemplarObserver.ObserveWithExemplar(value, prometheus.Labels{"trace_id": span.TraceID().String()})
+		return
+	}
+
+	observer.Observe(value)
+}
@@ -0,0 +1,67 @@
+package instrumentation
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+func TestMetrics(t *testing.T) {
+
+	metrics, err := New(prometheus.NewRegistry(), WithMetricsEndpoint("/metrics"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := mux.NewRouter()
+	router.Use(metrics.Middleware)
+	v2 := router.PathPrefix("/v2").Subrouter()
+	v2.HandleFunc("/person/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		w.Write([]byte(`{"firstname":"Ada"}`))
+	}).Methods(http.MethodGet, http.MethodPut)
+	router.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {})
+	router.NotFoundHandler = metrics.NotFoundHandler()
+	router.MethodNotAllowedHandler = metrics.MethodNotAllowedHandler(router)
+
+	serve := func(method, path, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		router.ServeHTTP(recorder, httptest.NewRequest(method, path, strings.NewReader(body)))
+		return recorder
+	}
+
+	serve(http.MethodPut, "/v2/person/1", `{"lastname":"Lovelace"}`)
+	serve(http.MethodGet, "/metrics", "")
+
+	if count := testutil.ToFloat64(metrics.requests.WithLabelValues("/v2/person/{id}", http.MethodPut, "v2", anonymous, noTenant)); count != 1 {
+		t.Errorf("expected 1 request to the route, got %v", count)
+	}
+	if count := testutil.CollectAndCount(metrics.requests); count != 1 {
+		t.Errorf("expected the metrics route not to be measured, got %d series", count)
+	}
+	if count := testutil.CollectAndCount(metrics.requestSize); count != 1 {
+		t.Errorf("expected the request size to be measured, got %d series", count)
+	}
+
+	notFound := serve(http.MethodGet, "/v3/people", "")
+	if notFound.Code != http.StatusNotFound || notFound.Header().Get(setContentType) != jsonType {
+		t.Errorf("expected a JSON 404, got %d %q", notFound.Code, notFound.Header().Get(setContentType))
+	}
+	if count := testutil.ToFloat64(metrics.requests.WithLabelValues(unmatched, http.MethodGet, "none", anonymous, noTenant)); count != 1 {
+		t.Errorf("expected 1 unmatched request, got %v", count)
+	}
+
+	notAllowed := serve(http.MethodDelete, "/v2/person/1", "")
+	if notAllowed.Code != http.StatusMethodNotAllowed || notAllowed.Header().Get(allowHeader) != "GET, PUT" {
+		t.Errorf("expected a 405 allowing GET, PUT, got %d %q", notAllowed.Code, notAllowed.Header().Get(allowHeader))
+	}
+	if count := testutil.ToFloat64(metrics.requests.WithLabelValues("/v2/person/{id}", http.MethodDelete, "v2", anonymous, noTenant)); count != 1 {
+		t.Errorf("expected the 405 to be labeled with the route template, got %v", count)
+	}
+}
@@ -0,0 +1,104 @@
+package instrumentation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	setContentType        = "content-type"
+	jsonType              = "application/json"
+	allowHeader           = "Allow"
+	errorNotFound         = "No route matches %s"
+	errorMethodNotAllowed = "Method %s is not allowed, expected one of: %s"
+)
+
+// NotFoundHandler answers the requests matching no route of a router with a
+// JSON 404, recorded with the path label "unmatched".
+func (m *Metrics) NotFoundHandler() http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		m.serve(response, request, unmatched, http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			writeMessage(response, http.StatusNotFound, fmt.Sprintf(errorNotFound, request.URL.Path))
+		}))
+	})
+}
+
+// MethodNotAllowedHandler answers the requests whose path matches a route of
+// router but not their method with a JSON 405 listing the allowed methods.
+// They are recorded with the template of the first route matching their path.
+func (m *Metrics) MethodNotAllowedHandler(router *mux.Router) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		path, methods := matchPath(router, request)
+
+		m.serve(response, request, path, http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+			allowed := strings.Join(methods, ", ")
+			response.Header().Set(allowHeader, allowed)
+			writeMessage(response, http.StatusMethodNotAllowed, fmt.Sprintf(errorMethodNotAllowed, request.Method, allowed))
+		}))
+	})
+}
+
+// matchPath returns the template of the first route of router matching the
+// path of request, whatever its method, and the methods of all of them.
+func matchPath(router *mux.Router, request *http.Request) (string, []string) {
+
+	path := unknown
+	var methods []string
+	seen := map[string]bool{}
+
+	_ = router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+
+		// subrouters have no handler, they match the prefix of their routes
+		if route.GetHandler() == nil {
+			return nil
+		}
+
+		pattern, err := route.GetPathRegexp()
+		if err != nil {
+			return nil
+		}
+
+		if matched, err := regexp.MatchString(pattern, request.URL.Path); err != nil || !matched {
+			return nil
+		}
+
+		routeMethods, err := route.GetMethods()
+		if err != nil {
+			// routes of any method
+			return nil
+		}
+
+		if path == unknown {
+			path, _ = route.GetPathTemplate()
+		}
+
+		for _, method := range routeMethods {
+			if !seen[method] {
+				seen[method] = true
+				methods = append(methods, method)
+			}
+		}
+
+		return nil
+	})
+
+	return path, methods
+}
+
+func writeMessage(response http.ResponseWriter, status int, message string) {
+
+	response.Header().Set(setContentType, jsonType)
+	response.WriteHeader(status)
+
+	// the client went away
+	_ = json.NewEncoder(response).Encode(struct {
+		Message string `json:"message"`
+	}{message})
+}
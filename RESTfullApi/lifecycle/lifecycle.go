@@ -0,0 +1,168 @@
+// Package lifecycle runs the servers of the service until it is asked to stop
+// and then shuts it down in order.
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+type (
+	// Hook is a step of the shutdown, it must return once ctx is done.
+	Hook func(ctx context.Context) error
+
+	// Lifecycle runs the shutdown hooks in the order they were added, within
+	// an overall deadline, once a signal is received or a server stops.
+	Lifecycle struct {
+		logger  *slog.Logger
+		timeout time.Duration
+		signals []os.Signal
+		mu      sync.Mutex
+		hooks   []namedHook
+		once    sync.Once
+		err     error
+	}
+
+	namedHook struct {
+		name string
+		hook Hook
+	}
+
+	option func(lifecycle *Lifecycle)
+)
+
+// New returns a lifecycle stopping on SIGINT and SIGTERM and giving the hooks
+// 10 seconds to run.
+func New(logger *slog.Logger, opts ...option) *Lifecycle {
+
+	lifecycle := &Lifecycle{
+		logger:  logger,
+		timeout: 10 * time.Second,
+		signals: []os.Signal{os.Interrupt, syscall.SIGTERM},
+	}
+
+	for i := range opts {
+		opts[i](lifecycle)
+	}
+
+	return lifecycle
+}
+
+// WithTimeout sets the overall deadline of the shutdown, the hooks still
+// running past it are given an expired context.
+func WithTimeout(timeout time.Duration) option {
+	return func(lifecycle *Lifecycle) {
+		lifecycle.timeout = timeout
+	}
+}
+
+func WithSignals(signals ...os.Signal) option {
+	return func(lifecycle *Lifecycle) {
+		lifecycle.signals = signals
+	}
+}
+
+// OnShutdown adds a hook run after those added before it.
+func (l *Lifecycle) OnShutdown(name string, hook Hook) {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.hooks = append(l.hooks, namedHook{name: name, hook: hook})
+}
+
+// Run starts each of servers and blocks until a signal is received, ctx is
+// done or a server stops, then it shuts down. The error joins those of the
+// server that stopped and of the hooks, a server returning
+// http.ErrServerClosed has stopped on request.
+func (l *Lifecycle) Run(ctx context.Context, servers ...func() error) error {
+
+	ctx, stop := signal.NotifyContext(ctx, l.signals...)
+	defer stop()
+
+	stopped := make(chan error, len(servers))
+	for _, serve := range servers {
+		go func(serve func() error) {
+			stopped <- serve()
+		}(serve)
+	}
+
+	var serveErr error
+
+	select {
+	case <-ctx.Done():
+		l.logger.Info("Shutting down", "cause", context.Cause(ctx))
+	case err := <-stopped:
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serveErr = fmt.Errorf("serving: %w", err)
+		}
+		l.logger.Info("Shutting down after a server stopped", "error", err)
+	}
+
+	// a second signal kills the process
+	stop()
+
+	return errors.Join(serveErr, l.Shutdown(context.Background()))
+}
+
+// Shutdown runs the hooks once, each of them even when one before failed, and
+// returns their errors joined. Later calls return the same errors.
+func (l *Lifecycle) Shutdown(ctx context.Context) error {
+
+	l.once.Do(func() {
+
+		ctx, cancel := context.WithTimeout(ctx, l.timeout)
+		defer cancel()
+
+		l.mu.Lock()
+		hooks := append([]namedHook(nil), l.hooks...)
+		l.mu.Unlock()
+
+		var errs []error
+
+		for _, h := range hooks {
+			start := time.Now()
+			err := h.hook(ctx)
+			if err != nil {
+				l.logger.Error("Shutdown hook failed", "hook", h.name, "took", time.Since(start), "error", err)
+				errs = append(errs, fmt.Errorf("%s: %w", h.name, err))
+				continue
+			}
+			l.logger.Debug("Shutdown hook done", "hook", h.name, "took", time.Since(start))
+		}
+
+		l.err = errors.Join(errs...)
+	})
+
+	return l.err
+}
+
+// Delay returns a hook waiting for d, to let the load balancers notice the
+// service is no longer ready before the servers stop accepting requests.
+func Delay(d time.Duration) Hook {
+
+	return func(ctx context.Context) error {
+
+		if d <= 0 {
+			return nil
+		}
+
+		timer := time.NewTimer(d)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+			return nil
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package lifecycle
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestRun(t *testing.T) {
+
+	lifecycle := New(slog.New(slog.NewTextHandler(io.Discard, nil)), WithSignals(syscall.SIGUSR1), WithTimeout(time.Second))
+
+	var order []string
+	errMongo := errors.New("disconnect failed")
+	closed := make(chan struct{})
+
+	lifecycle.OnShutdown("readiness", func(ctx context.Context) error {
+		order = append(order, "readiness")
+		return nil
+	})
+	lifecycle.OnShutdown("drain", Delay(10*time.Millisecond))
+	lifecycle.OnShutdown("http", func(ctx context.Context) error {
+		order = append(order, "http")
+		close(closed)
+		return nil
+	})
+	lifecycle.OnShutdown("mongo", func(ctx context.Context) error {
+		order = append(order, "mongo")
+		return errMongo
+	})
+	lifecycle.OnShutdown("tracing", func(ctx context.Context) error {
+		order = append(order, "tracing")
+		return nil
+	})
+
+	serve := func() error {
+		<-closed
+		return http.ErrServerClosed
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		syscall.Kill(syscall.Getpid(), syscall.SIGUSR1)
+	}()
+
+	err := lifecycle.Run(context.Background(), serve)
+
+	if !errors.Is(err, errMongo) {
+		t.Errorf("expected the error of the mongo hook, got %v", err)
+	}
+	if expected := []string{"readiness", "http", "mongo", "tracing"}; !reflect.DeepEqual(order, expected) {
+		t.Errorf("expected the hooks to run in order %v, got %v", expected, order)
+	}
+	if again := lifecycle.Shutdown(context.Background()); !errors.Is(again, errMongo) {
+		t.Errorf("expected a second shutdown to return the same error, got %v", again)
+	}
+	if len(order) != 4 {
+		t.Errorf("expected the hooks to run once, got %v", order)
+	}
+}
+
+func TestRunServerFailure(t *testing.T) {
+
+	lifecycle := New(slog.New(slog.NewTextHandler(io.Discard, nil)), WithSignals(syscall.SIGUSR1), WithTimeout(50*time.Millisecond))
+
+	lifecycle.OnShutdown("drain", Delay(time.Hour))
+
+	errListen := errors.New("address already in use")
+
+	err := lifecycle.Run(context.Background(), func() error { return errListen })
+
+	if !errors.Is(err, errListen) {
+		t.Errorf("expected the error of the server, got %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the drain to be cut by the deadline, got %v", err)
+	}
+}
@@ -0,0 +1,79 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"sync"
+)
+
+type (
+	// contextHandler adds the attributes attached to the context of each
+	// record, such as the ones of the request being served.
+	contextHandler struct {
+		slog.Handler
+	}
+
+	// fields are the attributes attached to a context, they are added while
+	// the request goes through its middlewares.
+	fields struct {
+		mu    sync.Mutex
+		attrs []slog.Attr
+	}
+
+	keyFields struct{}
+)
+
+// NewHandler returns a handler writing the records of level and above to w,
+// as JSON or text.
+func NewHandler(w io.Writer, format string, level slog.Leveler) (slog.Handler, error) {
+
+	opts := &slog.HandlerOptions{Level: level}
+
+	switch format {
+	case "json":
+		return contextHandler{slog.NewJSONHandler(w, opts)}, nil
+	case "text":
+		return contextHandler{slog.NewTextHandler(w, opts)}, nil
+	}
+
+	return nil, fmt.Errorf("unknown log format %q, expected json or text", format)
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+
+	if f, ok := ctx.Value(keyFields{}).(*fields); ok {
+		f.mu.Lock()
+		record.AddAttrs(f.attrs...)
+		f.mu.Unlock()
+	}
+
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
+}
+
+// NewContext returns a copy of ctx the attributes of the records logged with
+// it can be attached to, with AddAttrs.
+func NewContext(ctx context.Context, attrs ...slog.Attr) context.Context {
+	return context.WithValue(ctx, keyFields{}, &fields{attrs: attrs})
+}
+
+// AddAttrs attaches attrs to ctx, and to the contexts derived from it, they
+// are added to every record logged with them. It does nothing to the contexts
+// not returned by NewContext.
+func AddAttrs(ctx context.Context, attrs ...slog.Attr) {
+
+	if f, ok := ctx.Value(keyFields{}).(*fields); ok {
+		f.mu.Lock()
+		f.attrs = append(f.attrs, attrs...)
+		f.mu.Unlock()
+	}
+}
@@ -0,0 +1,47 @@
+package logging
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+)
+
+type levelBody struct {
+	Level string `json:"level"`
+}
+
+// LevelEndpoint answers the level of the logs to GET and changes it to the
+// one of a {"level": "debug"} body on PUT, until the configuration is
+// reloaded.
+func LevelEndpoint(logger *slog.Logger, level *slog.LevelVar) http.HandlerFunc {
+	return func(response http.ResponseWriter, request *http.Request) {
+
+		response.Header().Set("content-type", "application/json")
+
+		if request.Method == http.MethodPut {
+			var body levelBody
+			var next slog.Level
+			if err := json.NewDecoder(http.MaxBytesReader(response, request.Body, 1<<10)).Decode(&body); err != nil || next.UnmarshalText([]byte(body.Level)) != nil {
+				response.WriteHeader(http.StatusBadRequest)
+				writeJSON(response, request, logger, struct {
+					Message string `json:"message"`
+				}{`expected a body such as {"level": "debug"}, with the level debug, info, warn or error`})
+				return
+			}
+
+			previous := level.Level()
+			level.Set(next)
+			logger.InfoContext(request.Context(), "Log level changed", "from", previous, "to", next)
+		}
+
+		writeJSON(response, request, logger, levelBody{Level: strings.ToLower(level.Level().String())})
+	}
+}
+
+func writeJSON(response http.ResponseWriter, request *http.Request, logger *slog.Logger, body interface{}) {
+
+	if err := json.NewEncoder(response).Encode(body); err != nil {
+		logger.ErrorContext(request.Context(), "Error while writing the log level response", "error", err)
+	}
+}
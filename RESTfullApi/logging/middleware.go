@@ -0,0 +1,67 @@
+package logging
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/requestid"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+const requestServed = "request served"
+
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Middleware attaches the route, the method and the id of each request, set
+// by requestid.Middleware, and the trace and span ids of its span to its
+// context and logs a record once it was served, with its status and its
+// latency. Failed requests are logged as errors. The route serving the
+// metrics at metricsEndpoint is not logged.
+func Middleware(logger *slog.Logger, metricsEndpoint string) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+			route, err := mux.CurrentRoute(request).GetPathTemplate()
+			if err != nil {
+				route = "unknown"
+			}
+
+			if route == metricsEndpoint {
+				next.ServeHTTP(response, request)
+				return
+			}
+
+			attrs := []slog.Attr{slog.String("route", route), slog.String("method", request.Method)}
+			if id := requestid.FromContext(request.Context()); id != "" {
+				attrs = append(attrs, slog.String("request_id", id))
+			}
+			if span := trace.SpanContextFromContext(request.Context()); span.IsValid() {
+				attrs = append(attrs, slog.String("trace_id", span.TraceID().String()), slog.String("span_id", span.SpanID().String()))
+			}
+
+			ctx := NewContext(request.Context(), attrs...)
+
+			start := time.Now()
+			rw := &responseWriter{response, http.StatusOK}
+			next.ServeHTTP(rw, request.WithContext(ctx))
+
+			level := slog.LevelInfo
+			if rw.statusCode >= http.StatusInternalServerError {
+				level = slog.LevelError
+			}
+
+			logger.LogAttrs(ctx, level, requestServed, slog.Int("status", rw.statusCode), slog.Duration("latency", time.Since(start)))
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/requestid"
+
+	"github.com/gorilla/mux"
+)
+
+func TestMiddleware(t *testing.T) {
+
+	var output bytes.Buffer
+	handler, err := NewHandler(&output, "json", slog.LevelInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+	logger := slog.New(handler)
+
+	router := mux.NewRouter()
+	router.Use(Middleware(logger, "/metrics"))
+	router.HandleFunc("/person/{id}", func(w http.ResponseWriter, r *http.Request) {
+		AddAttrs(r.Context(), slog.String("tenant", "acme"))
+		w.WriteHeader(http.StatusNotFound)
+	})
+	router.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := httptest.NewRequest(http.MethodGet, "/person/1", nil)
+	request.Header.Set(requestid.Header, "abc")
+	requestid.Middleware(router).ServeHTTP(httptest.NewRecorder(), request)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	var record map[string]interface{}
+	decoder := json.NewDecoder(&output)
+	if err := decoder.Decode(&record); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]interface{}{
+		"msg":        requestServed,
+		"level":      "INFO",
+		"route":      "/person/{id}",
+		"method":     http.MethodGet,
+		"request_id": "abc",
+		"tenant":     "acme",
+		"status":     float64(http.StatusNotFound),
+	}
+	for key, value := range expected {
+		if record[key] != value {
+			t.Errorf("expected %s %v, got %v", key, value, record[key])
+		}
+	}
+	if _, ok := record["latency"]; !ok {
+		t.Error("expected the latency to be logged")
+	}
+
+	if decoder.More() {
+		t.Error("expected the metrics route not to be logged")
+	}
+}
+
+func TestNewHandlerUnknownFormat(t *testing.T) {
+
+	if _, err := NewHandler(&bytes.Buffer{}, "xml", slog.LevelInfo); err == nil {
+		t.Fatal("expected an error")
+	}
+}
@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"log"
+	"io"
+	"log/slog"
+	"net"
 	"net/http"
 	"os"
 	"strings"
@@ -12,126 +15,552 @@ import (
 
 	"github.com/gorilla/mux"
 
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
 	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/clients"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/config"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/gql"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/grpcserver"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/health"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/instrumentation"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/lifecycle"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/logging"
 	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/observability"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/peoplepb"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/profiler"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/tenant"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/tracing"
 
 	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/handlers"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/idempotency"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/ratelimit"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/requestid"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+	"google.golang.org/grpc"
 
 	"net/http/pprof"
 	// _ "net/http/pprof"
 )
 
-var (
-	envFilePath string
-	timeout     time.Duration
+// the API keys and the tenants are kept next to the people collection
+const (
+	apiKeysCollection         = "api_keys"
+	tenantsCollection         = "tenants"
+	idempotencyKeysCollection = "idempotency_keys"
 )
 
-func init() {
-	flag.StringVar(&envFilePath, "envFilePath", "../.env", "path to .env file")
-	flag.DurationVar(&timeout, "timeout", 10, "timeout in seconds")
-
+// peoplePermissions is the scope required by each route of the people API,
+// rbacFile can override it.
+var peoplePermissions = auth.Permissions{
+	"getPerson":       auth.ScopeRead,
+	"listPeople":      auth.ScopeRead,
+	"getPeopleByName": auth.ScopeRead,
+	"createPerson":    auth.ScopeWrite,
+	"deletePerson":    auth.ScopeWrite,
+	"updatePerson":    auth.ScopeWrite,
 }
 
 func main() {
+	os.Exit(run(os.Args[1:]))
+}
 
-	flag.Parse()
+// run serves the API, or runs the command of args, and returns the exit code.
+// The resources set up along the way are released when it returns, once the
+// servers stopped or a later step failed.
+func run(args []string) (code int) {
 
-	logger := log.New(os.Stdout, "mongoDBAtlas-api ", log.LstdFlags)
+	// the reloads parse the same flags
+	flags := args
 
-	if err := prometheus.Register(observability.TotalRequests); err != nil {
-		logger.Println("Faled to register totalRequests:", err)
+	cfg, args, err := config.Load(args, os.Getenv, os.Stderr)
+	if errors.Is(err, flag.ErrHelp) {
+		return 0
+	}
+	if err != nil {
+		return fatal(slog.Default(), "Error while loading the configuration", err)
+	}
 
+	// dump writes to stdout by default
+	output := os.Stdout
+	if len(args) > 0 {
+		output = os.Stderr
 	}
-	if err := prometheus.Register(observability.ResponseStatus); err != nil {
-		logger.Println("Faled to register responseStatus:", err)
 
+	logLevel := new(slog.LevelVar)
+	// validated along with the configuration
+	level, _ := cfg.Log.SlogLevel()
+	logLevel.Set(level)
+	logHandler, _ := logging.NewHandler(output, cfg.Log.Format, logLevel)
+
+	logger := slog.New(logHandler)
+	slog.SetDefault(logger)
+
+	if len(args) > 0 {
+		return runCommand(logger, cfg, args)
 	}
-	if err := prometheus.Register(observability.HTTPDuration); err != nil {
-		logger.Println("Faled to register httpDuration:", err)
 
+	// the metrics are served from a registry of their own, with the Go
+	// runtime and process collectors
+	registry := instrumentation.NewRegistry()
+
+	httpMetrics, err := instrumentation.New(registry,
+		instrumentation.WithDurationBuckets(cfg.HTTP.DurationBuckets),
+		instrumentation.WithSizeBuckets(cfg.HTTP.SizeBuckets),
+		instrumentation.WithMetricsEndpoint(cfg.HTTP.MetricsEndpoint),
+	)
+	if err != nil {
+		return fatal(logger, "Error while registering the HTTP metrics", err)
 	}
 
-	client, err := clients.ConnectClient(logger, envFilePath)
+	for _, collector := range []prometheus.Collector{
+		observability.ThrottledRequests,
+		observability.CacheLookups,
+		observability.CacheEvictions,
+		observability.ConfigGeneration,
+		observability.ConfigReloads,
+		observability.MongoCommandDuration,
+		observability.MongoCommandFailures,
+		observability.MongoPoolConnections,
+		observability.MongoPoolConnectionsInUse,
+		observability.MongoPoolWaitDuration,
+		observability.MongoPoolCheckoutFailures,
+		observability.GRPCTotalRequests,
+		observability.GRPCResponseStatus,
+		observability.GRPCDuration,
+	} {
+		if err := registry.Register(collector); err != nil {
+			return fatal(logger, "Error while registering a metric", err)
+		}
+	}
 
+	tracerProvider, shutdownTracing, err := tracing.NewProvider(context.Background(), cfg.Tracing.Exporter,
+		tracing.WithEndpoint(cfg.Tracing.Endpoint),
+		tracing.WithFile(cfg.Tracing.File),
+		tracing.WithSampleRatio(cfg.Tracing.SampleRatio),
+		tracing.WithServiceName(cfg.Tracing.ServiceName),
+	)
 	if err != nil {
-		logger.Fatalf("Error while connecting to the mongoDB client: %v", err)
+		return fatal(logger, "Error while setting up tracing", err)
 	}
+	// the spans are flushed last, after those of the mongo commands
+	defer release(&code, logger, "tracing", cfg.HTTP.ShutdownTimeout, shutdownTracing)
 
-	collection := client.Database("thepolyglotdeveloper").Collection("people")
+	client, err := clients.ConnectClient(logger, cfg.Mongo.URI, options.Client().
+		SetMonitor(clients.CommandMonitors(tracing.CommandMonitor(tracerProvider), observability.CommandMonitor())).
+		SetPoolMonitor(observability.PoolMonitor()))
 
-	EndpointHandlerPost := handlers.NewEndpointHandler(logger, collection)
+	if err != nil {
+		return fatal(logger, "Error while connecting to the mongoDB client", err)
+	}
+	defer release(&code, logger, "mongo", cfg.HTTP.ShutdownTimeout, func(ctx context.Context) error {
+		return clients.DisconnectClient(ctx, client, logger)
+	})
+
+	// validated along with the configuration
+	tenantSources, _ := tenant.ParseSources(cfg.Tenant.Sources)
+
+	// the default tenant is served from the database and collection of the
+	// flags, as before tenants existed
+	var defaultTenant *tenant.Tenant
+	if cfg.Tenant.Default != "" {
+		defaultTenant = &tenant.Tenant{ID: cfg.Tenant.Default, Name: cfg.Tenant.Default, Isolation: tenant.IsolationCollection, Database: cfg.Mongo.Database, Collection: cfg.Mongo.Collection}
+	}
 
-	EndpointHandlerGet := handlers.NewEndpointHandler(logger, collection, handlers.WithTimeout(10*time.Second))
+	tenantRegistry := tenant.NewMongoRegistry(client.Database(cfg.Mongo.Database).Collection(tenantsCollection), time.Minute)
+
+	resolver := tenant.NewResolver(logger, tenantRegistry, defaultTenant,
+		tenant.WithSources(tenantSources),
+		tenant.WithHeader(cfg.Tenant.Header),
+		tenant.WithBaseDomain(cfg.Tenant.BaseDomain),
+	)
+
+	var personStore store.PersonStore = tenant.NewStore(client)
+	if cfg.Cache.Size > 0 {
+		personStore = store.NewCachedPersonStore(personStore, cfg.Cache.Size, cfg.Cache.TTL,
+			store.WithStaleIfError(cfg.Cache.StaleIfError),
+			store.WithPartition(func(ctx context.Context) string {
+				if t, ok := tenant.FromContext(ctx); ok {
+					return t.ID
+				}
+				return ""
+			}),
+		)
+	}
+
+	checker := health.NewChecker(logger, health.WithTimeout(cfg.HTTP.HealthTimeout))
+	checker.Add("mongo", func(ctx context.Context) error {
+		return client.Ping(ctx, readpref.Primary())
+	})
+
+	keyStore := auth.NewMongoKeyStore(client.Database(cfg.Mongo.Database).Collection(apiKeysCollection))
+
+	indexCtx, cancelIndex := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancelIndex()
+	if err := keyStore.EnsureIndexes(indexCtx); err != nil {
+		return fatal(logger, "Error while creating the API key indexes", err)
+	}
+
+	idempotencyStore := idempotency.NewMongoStore(client.Database(cfg.Mongo.Database).Collection(idempotencyKeysCollection))
+	if err := idempotencyStore.EnsureIndexes(indexCtx); err != nil {
+		return fatal(logger, "Error while creating the idempotency key indexes", err)
+	}
+	cancelIndex()
+
+	idempotencyHandler := idempotency.NewHandler(logger, idempotencyStore, idempotency.WithTTL(cfg.Idempotency.TTL), idempotency.WithMaxBodySize(cfg.HTTP.MaxBodySize))
+
+	rbac, err := auth.LoadRBAC(cfg.Auth.RBACFile, auth.RBAC{Roles: auth.DefaultRoles, Permissions: peoplePermissions})
+	if err != nil {
+		return fatal(logger, "Error while loading the RBAC file", err)
+	}
+
+	var verifier *auth.JWTVerifier
+	if cfg.Auth.JWKSSource != "" {
+		verifier = auth.NewJWTVerifier(auth.NewKeySet(cfg.Auth.JWKSSource),
+			auth.WithIssuer(cfg.Auth.Issuer),
+			auth.WithAudience(cfg.Auth.Audience),
+			auth.WithRolesClaim(cfg.Auth.RolesClaim),
+			auth.WithTenantClaim(cfg.Auth.TenantClaim),
+			auth.WithRoles(rbac.Roles),
+		)
+		logger.Info("Accepting bearer tokens", "jwks", cfg.Auth.JWKSSource)
+	}
+
+	authenticator := auth.NewAuthenticator(logger, keyStore, auth.WithJWT(verifier))
+
+	require := authenticator.Require
+	authorize := authenticator.Authorize(rbac.Permissions)
+	if !cfg.Auth.Enabled {
+		logger.Warn("Authentication is disabled")
+		noAuth := func(next http.Handler) http.Handler { return next }
+		require = func(auth.Scope) mux.MiddlewareFunc { return noAuth }
+		authorize = noAuth
+	}
+
+	rateLimits, err := loadRateLimits(cfg)
+	if err != nil {
+		return fatal(logger, "Error while loading the rate limits", err)
+	}
+
+	limiter := ratelimit.NewLimiter(logger, rateLimits, ratelimit.WithProxyHeader(cfg.RateLimit.ProxyHeader))
+
+	cors := handlers.NewCORS(cfg.HTTP.CORSOrigins)
+
+	// a reloaded configuration must be valid, rate limits included, before
+	// any of its settings is applied. The rate limits validated by the load
+	// are the ones applied, Reload runs the load and the hooks under its lock.
+	var nextRateLimits ratelimit.Config
+	live := config.NewLive(logger, cfg, func() (config.Config, error) {
+		next, _, err := config.Load(flags, os.Getenv, io.Discard)
+		if err != nil {
+			return config.Config{}, err
+		}
+		rateLimits, err := loadRateLimits(next)
+		if err != nil {
+			return config.Config{}, fmt.Errorf("loading the rate limits: %w", err)
+		}
+		nextRateLimits = rateLimits
+		return next, nil
+	})
+
+	live.OnReload(func(next config.Config) {
+		level, _ := next.Log.SlogLevel()
+		logLevel.Set(level)
+		cors.SetOrigins(next.HTTP.CORSOrigins)
+		limiter.SetConfig(nextRateLimits)
+	})
+	watchCtx, stopWatching := context.WithCancel(context.Background())
+	defer stopWatching()
+	live.WatchSignals(watchCtx)
 
 	router := mux.NewRouter()
 
+	router.Use(tracing.Middleware(tracerProvider, cfg.HTTP.MetricsEndpoint))
+	router.Use(httpMetrics.Middleware)
+	router.Use(logging.Middleware(logger, cfg.HTTP.MetricsEndpoint))
+
+	// the requests matching no route get JSON errors and are measured too
+	router.NotFoundHandler = httpMetrics.NotFoundHandler()
+	router.MethodNotAllowedHandler = httpMetrics.MethodNotAllowedHandler(router)
+
+	// validated along with the configuration
+	v1Sunset, _ := cfg.HTTP.Sunset()
+
+	v1Router := router.PathPrefix(handlers.V1.PathPrefix()).Subrouter()
+	v1Router.Use(handlers.MiddlewareDeprecation(handlers.V2, v1Sunset))
+	registerPeopleRoutes(v1Router, handlers.V1, logger, live, personStore, idempotencyHandler.Middleware, limiter.AddressMiddleware, authorize, limiter.Middleware, resolver.Middleware)
+
+	// the routes of the API before it was versioned are kept as an alias of
+	// /v1, deprecated along with it
+	unversionedRouter := router.MatcherFunc(unversionedPeoplePath).Subrouter()
+	unversionedRouter.Use(handlers.MiddlewareDeprecation(handlers.V2, v1Sunset))
+	registerPeopleRoutes(unversionedRouter, handlers.V1, logger, live, personStore, idempotencyHandler.Middleware, limiter.AddressMiddleware, authorize, limiter.Middleware, resolver.Middleware)
+
+	v2Router := router.PathPrefix(handlers.V2.PathPrefix()).Subrouter()
+	v2Handler := registerPeopleRoutes(v2Router, handlers.V2, logger, live, personStore, idempotencyHandler.Middleware, limiter.AddressMiddleware, authorize, limiter.Middleware, resolver.Middleware)
+
+	schema, err := gql.NewSchema(personStore)
+	if err != nil {
+		return fatal(logger, "Error while building the graphql schema", err)
+	}
+
+	canMutate := func(r *http.Request) bool {
+		return !cfg.Auth.Enabled || auth.HasScope(r.Context(), auth.ScopeWrite)
+	}
+
+	graphqlHandler := gql.NewHandler(logger, schema, gql.WithMaxDepth(cfg.GraphQL.MaxDepth), gql.WithMaxComplexity(cfg.GraphQL.MaxComplexity), gql.WithMaxBodySize(cfg.HTTP.MaxBodySize), gql.WithMutationAuthorizer(canMutate))
+	router.Handle("/graphql", v2Handler.MiddlewareDeadline(limiter.AddressMiddleware(require(auth.ScopeRead)(limiter.Middleware(resolver.Middleware(graphqlHandler)))))).Methods(http.MethodGet, http.MethodPost).Name("graphql")
+
+	// the operational endpoints are served on a listener of their own, so
+	// that the public one only serves the people API
+	adminRouter := mux.NewRouter()
+	adminRouter.Use(auth.RequireToken(logger, cfg.Admin.Token, "/healthz", "/readyz", cfg.HTTP.MetricsEndpoint))
+
+	// the probes and the metrics are not authenticated, so orchestrators and
+	// prometheus can reach them
+	adminRouter.HandleFunc("/healthz", checker.LiveEndpoint).Methods(http.MethodGet)
+	adminRouter.HandleFunc("/readyz", checker.ReadyEndpoint).Methods(http.MethodGet)
+
+	// the exemplars of the histograms are only exposed in the OpenMetrics format
+	adminRouter.Handle(cfg.HTTP.MetricsEndpoint, promhttp.HandlerFor(registry, promhttp.HandlerOpts{EnableOpenMetrics: true, Registry: registry})).Methods(http.MethodGet)
+
 	// debug pprof
-	router.HandleFunc("/debug/pprof/", http.HandlerFunc(pprof.Index))
-	router.HandleFunc("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
-	router.HandleFunc("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
-	router.HandleFunc("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
-	router.Handle("/debug/pprof/heap", pprof.Handler("heap"))
-	router.Handle("/debug/pprof/goroutine", pprof.Handler("goroutine"))
-	router.Handle("/debug/pprof/threadcreate", pprof.Handler("threadcreate"))
-	router.Handle("/debug/pprof/block", pprof.Handler("block"))
+	debugRouter := adminRouter.PathPrefix("/debug/pprof").Subrouter()
+	debugRouter.Use(require(auth.ScopeAdmin))
+	debugRouter.HandleFunc("/", http.HandlerFunc(pprof.Index))
+	debugRouter.HandleFunc("/profile", http.HandlerFunc(pprof.Profile))
+	debugRouter.HandleFunc("/symbol", http.HandlerFunc(pprof.Symbol))
+	debugRouter.HandleFunc("/trace", http.HandlerFunc(pprof.Trace))
+	debugRouter.Handle("/heap", pprof.Handler("heap"))
+	debugRouter.Handle("/goroutine", pprof.Handler("goroutine"))
+	debugRouter.Handle("/threadcreate", pprof.Handler("threadcreate"))
+	debugRouter.Handle("/block", pprof.Handler("block"))
 	// allocs
-	router.Handle("/debug/pprof/allocs", pprof.Handler("allocs"))
+	debugRouter.Handle("/allocs", pprof.Handler("allocs"))
 
-	router.Use(observability.PrometheusMiddleware)
+	keysRouter := adminRouter.PathPrefix("/admin/keys").Subrouter()
+	keysRouter.Use(require(auth.ScopeAdmin))
+	keysRouter.HandleFunc("", authenticator.IssueKeyEndpoint).Methods(http.MethodPost)
+	keysRouter.HandleFunc("", authenticator.ListKeysEndpoint).Methods(http.MethodGet)
+	keysRouter.HandleFunc("/{id}", authenticator.RevokeKeyEndpoint).Methods(http.MethodDelete)
 
-	getRouter := router.Methods(http.MethodGet).Subrouter()
+	configRouter := adminRouter.PathPrefix("/admin/config").Subrouter()
+	configRouter.Use(require(auth.ScopeAdmin))
+	configRouter.HandleFunc("", live.ConfigEndpoint).Methods(http.MethodGet)
+	configRouter.HandleFunc("/reload", live.ReloadEndpoint).Methods(http.MethodPost)
 
-	nameEndpoint := os.Getenv("NAME_ENDPOINT")
+	logRouter := adminRouter.PathPrefix("/admin/log").Subrouter()
+	logRouter.Use(require(auth.ScopeAdmin))
+	logRouter.HandleFunc("/level", logging.LevelEndpoint(logger, logLevel)).Methods(http.MethodGet, http.MethodPut)
 
-	getRouter.HandleFunc("/person/{id}", EndpointHandlerGet.GetPersonByIdEndpoint)
-	getRouter.HandleFunc("/people", EndpointHandlerGet.GetPeopleEndpoint)
-	getRouter.HandleFunc(fmt.Sprintf("/personName/{%v}", nameEndpoint), EndpointHandlerGet.GetPersonByNameEndpoint)
-	getRouter.Handle(os.Getenv("METRICS_ENDPOINT"), promhttp.Handler())
+	tenantHandler := tenant.NewHandler(logger, tenantRegistry, client, cfg.Mongo.Database, cfg.Mongo.Collection, defaultTenant)
+
+	tenantsRouter := adminRouter.PathPrefix("/admin/tenants").Subrouter()
+	tenantsRouter.Use(require(auth.ScopeAdmin))
+	tenantsRouter.HandleFunc("", tenantHandler.ProvisionEndpoint).Methods(http.MethodPost)
+	tenantsRouter.HandleFunc("", tenantHandler.ListEndpoint).Methods(http.MethodGet)
+	tenantsRouter.HandleFunc("/{id}", tenantHandler.GetEndpoint).Methods(http.MethodGet)
+
+	bindAddress := cfg.HTTP.BindAddress
+
+	s := http.Server{
+		Addr:         bindAddress,                                    // configure the bind address
+		Handler:      cors.Handler(requestid.Middleware(router)),     // set the default handler
+		ErrorLog:     slog.NewLogLogger(logHandler, slog.LevelError), // set the logger for the server
+		ReadTimeout:  cfg.HTTP.ReadTimeout,                           // max time to read request from the client
+		WriteTimeout: cfg.HTTP.WriteTimeout,                          // max time to write response to the client
+		IdleTimeout:  cfg.HTTP.IdleTimeout,                           // max time for connections using TCP Keep-Alive
+	}
+
+	adminServer := http.Server{
+		Addr:         cfg.Admin.BindAddress,
+		Handler:      requestid.Middleware(adminRouter),
+		ErrorLog:     slog.NewLogLogger(logHandler, slog.LevelError),
+		ReadTimeout:  cfg.HTTP.ReadTimeout,
+		WriteTimeout: cfg.HTTP.WriteTimeout, // pprof profiles for 30 seconds by default
+		IdleTimeout:  cfg.HTTP.IdleTimeout,
+	}
+
+	grpcBindAddress := cfg.GRPC.BindAddress
+
+	listener, err := net.Listen("tcp", grpcBindAddress)
+	if err != nil {
+		return fatal(logger, "Error while listening", err, "address", grpcBindAddress)
+	}
+	// closed by the gRPC server once it serves
+	defer listener.Close()
+
+	var grpcOptions []grpc.ServerOption
+	if cfg.Auth.Enabled {
+		grpcOptions = append(grpcOptions,
+			grpc.ChainUnaryInterceptor(authenticator.UnaryServerInterceptor(grpcserver.MethodScopes)),
+			grpc.ChainStreamInterceptor(authenticator.StreamServerInterceptor(grpcserver.MethodScopes)),
+		)
+	}
+
+	personService := peoplepb.PersonService_ServiceDesc.ServiceName
+	grpcOptions = append(grpcOptions,
+		grpc.ChainUnaryInterceptor(resolver.UnaryServerInterceptor(personService)),
+		grpc.ChainStreamInterceptor(resolver.StreamServerInterceptor(personService)),
+	)
+
+	grpcServer, healthServer := grpcserver.NewServer(grpcserver.NewPersonServer(logger, personStore), grpcOptions...)
+
+	lc := lifecycle.New(logger, lifecycle.WithTimeout(cfg.HTTP.ShutdownTimeout))
+
+	// readiness fails first so that no new traffic is routed to the servers
+	// while they drain the in-flight requests
+	lc.OnShutdown("readiness", func(context.Context) error {
+		checker.SetShuttingDown()
+		healthServer.Shutdown()
+		return nil
+	})
+	lc.OnShutdown("drain", lifecycle.Delay(cfg.HTTP.DrainDelay))
+	lc.OnShutdown("http", s.Shutdown)
+	lc.OnShutdown("grpc", func(ctx context.Context) error {
+		return grpcserver.Stop(ctx, grpcServer, healthServer)
+	})
+	// the admin server answers the probes and serves the metrics until the
+	// others stopped
+	lc.OnShutdown("admin", adminServer.Shutdown)
+	// the profiles refresh default.pgo, see pgo merge
+	if cfg.Profiling.Dir != "" {
+		prof := profiler.New(logger, cfg.Profiling.Dir,
+			profiler.WithInterval(cfg.Profiling.Interval),
+			profiler.WithDuration(cfg.Profiling.Duration),
+			profiler.WithKeep(cfg.Profiling.Keep),
+		)
+		if err := prof.Start(); err != nil {
+			return fatal(logger, "Error while starting the profiler", err, "dir", cfg.Profiling.Dir)
+		}
+		lc.OnShutdown("profiler", prof.Stop)
+	}
+
+	const SERVER_STARTING = "Starting server"
+	port := strings.Split(bindAddress, ":")[1]
+	logger.Info(SERVER_STARTING, "port", port)
+	logger.Info("Starting gRPC server", "address", grpcBindAddress)
+
+	logger.Info("Starting admin server", "address", cfg.Admin.BindAddress)
+
+	err = lc.Run(context.Background(), s.ListenAndServe, adminServer.ListenAndServe, func() error {
+		return grpcServer.Serve(listener)
+	})
+	if err != nil {
+		logger.Error("Error while shutting down", "error", err)
+		return 1
+	}
+
+	logger.Info("Exiting")
+
+	return 0
+}
+
+// registerPeopleRoutes mounts the people API of a version on router, the
+// middlewares run in order before those of the routes, the rate limit by
+// address and then authorization, which looks the permission table up by
+// route name. The deadline of the route is set before all of them.
+// idempotent runs after them on the POST routes. It returns the handler of
+// the routes, whose deadline middleware also bounds the routes of
+// routeTimeouts mounted elsewhere.
+func registerPeopleRoutes(router *mux.Router, version handlers.APIVersion, logger *slog.Logger, live *config.Live, personStore store.PersonStore, idempotent mux.MiddlewareFunc, middlewares ...mux.MiddlewareFunc) *handlers.EndpointHandler {
+
+	cfg := live.Current().HTTP
+
+	EndpointHandler := handlers.NewEndpointHandler(logger, personStore, handlers.WithRouteTimeouts(routeTimeouts(cfg), cfg.WriteHandlerTimeout), handlers.WithAPIVersion(version),
+		handlers.WithMaxBodySize(cfg.MaxBodySize), handlers.WithStrictDecoding(cfg.StrictDecoding), handlers.WithNameEndpoint(cfg.NameEndpoint))
+
+	live.OnReload(func(next config.Config) {
+		EndpointHandler.SetRouteTimeouts(routeTimeouts(next.HTTP), next.HTTP.WriteHandlerTimeout)
+	})
+
+	// the deadline covers the middlewares too, like the tenant lookup
+	middlewares = append([]mux.MiddlewareFunc{EndpointHandler.MiddlewareDeadline}, middlewares...)
+
+	getRouter := router.Methods(http.MethodGet).Subrouter()
+	getRouter.Use(middlewares...)
+
+	getRouter.HandleFunc("/person/{id}", EndpointHandler.GetPersonByIdEndpoint).Name("getPerson")
+	getRouter.HandleFunc("/people", EndpointHandler.GetPeopleEndpoint).Name("listPeople")
+	getRouter.HandleFunc(fmt.Sprintf("/personName/{%v}", cfg.NameEndpoint), EndpointHandler.GetPersonByNameEndpoint).Name("getPeopleByName")
 
 	postRouter := router.Methods(http.MethodPost).Subrouter()
-	postRouter.HandleFunc("/person", EndpointHandlerPost.CreatePersonEndpoint)
-	postRouter.Use(EndpointHandlerPost.MiddlewareValidateProduct)
+	postRouter.HandleFunc("/person", EndpointHandler.CreatePersonEndpoint).Name("createPerson")
+	postRouter.Use(middlewares...)
+	postRouter.Use(idempotent)
+	postRouter.Use(EndpointHandler.MiddlewareValidateProduct)
 
 	delRouter := router.Methods(http.MethodDelete).Subrouter()
-	delRouter.HandleFunc("/person/{id}", EndpointHandlerPost.DeletePersonByIdEndpoint)
+	delRouter.HandleFunc("/person/{id}", EndpointHandler.DeletePersonByIdEndpoint).Name("deletePerson")
+	delRouter.Use(middlewares...)
 
 	updateRouter := router.Methods(http.MethodPut).Subrouter()
-	updateRouter.HandleFunc("/person/{id}", EndpointHandlerPost.UpdatePersonByIdEndpoint)
-	updateRouter.Use(EndpointHandlerPost.MiddlewareValidateUpdateRequest)
+	updateRouter.HandleFunc("/person/{id}", EndpointHandler.UpdatePersonByIdEndpoint).Name("updatePerson")
+	updateRouter.Use(middlewares...)
+	updateRouter.Use(EndpointHandler.MiddlewareValidateUpdateRequest)
 
-	const BIND_ADDRESS = "BIND_ADDRESS"
-	bindAddress := os.Getenv(BIND_ADDRESS)
+	return EndpointHandler
+}
 
-	if bindAddress == "" {
-		const Localhost = "127.0.0.1:8080"
-		bindAddress = Localhost
-	}
+// unversionedPeoplePath matches the paths of the people routes mounted at the
+// root.
+func unversionedPeoplePath(request *http.Request, _ *mux.RouteMatch) bool {
 
-	s := http.Server{
-		Addr:         bindAddress,       // configure the bind address
-		Handler:      router,            // set the default handler
-		ErrorLog:     logger,            // set the logger for the server
-		ReadTimeout:  30 * time.Second,  // max time to read request from the client
-		WriteTimeout: 300 * time.Second, // max time to write response to the client
-		IdleTimeout:  120 * time.Second, // max time for connections using TCP Keep-Alive
-	}
+	path := request.URL.Path
+
+	return path == "/people" || path == "/person" || strings.HasPrefix(path, "/person/") || strings.HasPrefix(path, "/personName/")
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout*time.Second)
+// fatal logs msg with err and args as an error and returns the exit code of
+// run.
+func fatal(logger *slog.Logger, msg string, err error, args ...interface{}) int {
+
+	logger.Error(msg, append(args, "error", err)...)
+	return 1
+}
+
+// release runs shutdown within timeout when run returns, and sets the exit
+// code to 1 when it fails.
+func release(code *int, logger *slog.Logger, name string, timeout time.Duration, shutdown func(context.Context) error) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
 	defer cancel()
 
-	clients.CtrlCHandler(ctx, client, logger, &s)
+	if err := shutdown(ctx); err != nil {
+		logger.Error("Error while releasing", "resource", name, "error", err)
+		*code = 1
+	}
+}
+
+// loadRateLimits returns the rate limits of cfg, the limits of its file over
+// its default limit.
+func loadRateLimits(cfg config.Config) (ratelimit.Config, error) {
+	return ratelimit.LoadConfig(cfg.RateLimit.File, ratelimit.Limit{Requests: cfg.RateLimit.PerMinute, Per: time.Minute})
+}
 
-	const SERVER_STARTING = "Starting server on port %s"
-	port := strings.Split(bindAddress, ":")[1]
-	logger.Printf(SERVER_STARTING, port)
+// routeTimeouts returns the deadline of each people route and of graphql, the
+// routes reading people default to the read timeout and the others to the
+// write one. graphql, mostly queries, defaults to the read timeout. The
+// routes missing from it get the write timeout.
+func routeTimeouts(cfg config.HTTP) map[string]time.Duration {
+
+	timeouts := make(map[string]time.Duration, len(peoplePermissions)+1)
+	timeouts["graphql"] = cfg.ReadHandlerTimeout
+
+	for route, scope := range peoplePermissions {
+		timeouts[route] = cfg.WriteHandlerTimeout
+		if scope == auth.ScopeRead {
+			timeouts[route] = cfg.ReadHandlerTimeout
+		}
+	}
 
-	err = s.ListenAndServe()
-	if err == http.ErrServerClosed {
-		logger.Println("Server closed under request")
+	for route, timeout := range cfg.RouteTimeouts {
+		timeouts[route] = timeout
 	}
 
+	return timeouts
 }
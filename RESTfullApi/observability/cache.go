@@ -0,0 +1,29 @@
+package observability
+
+import "github.com/prometheus/client_golang/prometheus"
+
+const (
+	CacheHit   = "hit"
+	CacheMiss  = "miss"
+	CacheStale = "stale"
+
+	EvictedCapacity    = "capacity"
+	EvictedExpired     = "expired"
+	EvictedInvalidated = "invalidated"
+)
+
+var CacheLookups = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "person_cache_lookups_total",
+		Help: "Number of person cache lookups by result, stale lookups served an expired person because the store failed.",
+	},
+	[]string{"result"},
+)
+
+var CacheEvictions = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "person_cache_evictions_total",
+		Help: "Number of people dropped from the person cache by reason.",
+	},
+	[]string{"reason"},
+)
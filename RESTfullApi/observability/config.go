@@ -0,0 +1,16 @@
+package observability
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var ConfigGeneration = prometheus.NewGauge(prometheus.GaugeOpts{
+	Name: "config_generation",
+	Help: "Generation of the configuration in use, incremented by every successful reload.",
+})
+
+var ConfigReloads = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "config_reloads_total",
+		Help: "Number of configuration reloads by result.",
+	},
+	[]string{"result"},
+)
@@ -0,0 +1,67 @@
+package observability
+
+import (
+	"context"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	unaryType  = "unary"
+	streamType = "stream"
+)
+
+var GRPCTotalRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "grpc_requests_total",
+		Help: "Number of gRPC requests.",
+	},
+	[]string{"method", "type"},
+)
+
+var GRPCResponseStatus = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "grpc_response_status",
+		Help: "Status code of gRPC responses",
+	},
+	[]string{"code", "method"},
+)
+
+var GRPCDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "grpc_response_time_seconds",
+	Help: "Duration of gRPC requests, for streams until the stream ends.",
+}, []string{"method", "type"})
+
+// UnaryServerInterceptor records the gRPC counterparts of the metrics of
+// instrumentation.Metrics.Middleware for unary calls.
+func UnaryServerInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+	timer := prometheus.NewTimer(GRPCDuration.WithLabelValues(info.FullMethod, unaryType))
+
+	resp, err := handler(ctx, req)
+
+	GRPCResponseStatus.WithLabelValues(status.Code(err).String(), info.FullMethod).Inc()
+	GRPCTotalRequests.WithLabelValues(info.FullMethod, unaryType).Inc()
+
+	timer.ObserveDuration()
+
+	return resp, err
+}
+
+// StreamServerInterceptor records the gRPC counterparts of the metrics of
+// instrumentation.Metrics.Middleware for streaming calls.
+func StreamServerInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+	timer := prometheus.NewTimer(GRPCDuration.WithLabelValues(info.FullMethod, streamType))
+
+	err := handler(srv, ss)
+
+	GRPCResponseStatus.WithLabelValues(status.Code(err).String(), info.FullMethod).Inc()
+	GRPCTotalRequests.WithLabelValues(info.FullMethod, streamType).Inc()
+
+	timer.ObserveDuration()
+
+	return err
+}
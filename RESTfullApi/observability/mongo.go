@@ -0,0 +1,150 @@
+package observability
+
+import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// commandCollections holds the collection of the commands in flight, by
+// request id, the finished events do not carry the command.
+type commandCollections struct {
+	mu          sync.Mutex
+	collections map[int64]string
+}
+
+const unknownCode = "unknown"
+
+var MongoCommandDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name: "mongo_command_duration_seconds",
+	Help: "Duration of the mongo commands, failed ones included.",
+}, []string{"command", "collection"})
+
+var MongoCommandFailures = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "mongo_command_failures_total",
+		Help: "Number of failed mongo commands by server error code name.",
+	},
+	[]string{"command", "code"},
+)
+
+var MongoPoolConnections = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "mongo_pool_connections",
+		Help: "Number of open connections of the pool of each server.",
+	},
+	[]string{"address"},
+)
+
+var MongoPoolConnectionsInUse = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "mongo_pool_connections_in_use",
+		Help: "Number of connections of the pool of each server checked out by an operation.",
+	},
+	[]string{"address"},
+)
+
+var MongoPoolWaitDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+	Name:    "mongo_pool_wait_duration_seconds",
+	Help:    "Time spent checking a connection out of the pool, failed checkouts included.",
+	Buckets: []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1, 5},
+}, []string{"address"})
+
+var MongoPoolCheckoutFailures = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "mongo_pool_checkout_failures_total",
+		Help: "Number of failed connection checkouts by reason, such as timeout or poolClosed.",
+	},
+	[]string{"address", "reason"},
+)
+
+// CommandMonitor returns a monitor recording the duration and the failures
+// of the mongo commands.
+func CommandMonitor() *event.CommandMonitor {
+
+	c := &commandCollections{collections: map[int64]string{}}
+
+	return &event.CommandMonitor{
+		Started: func(_ context.Context, evt *event.CommandStartedEvent) {
+			c.mu.Lock()
+			c.collections[evt.RequestID] = collectionName(evt.Command, evt.CommandName)
+			c.mu.Unlock()
+		},
+		Succeeded: func(_ context.Context, evt *event.CommandSucceededEvent) {
+			MongoCommandDuration.WithLabelValues(evt.CommandName, c.take(evt.RequestID)).Observe(evt.Duration.Seconds())
+		},
+		Failed: func(_ context.Context, evt *event.CommandFailedEvent) {
+			MongoCommandDuration.WithLabelValues(evt.CommandName, c.take(evt.RequestID)).Observe(evt.Duration.Seconds())
+			MongoCommandFailures.WithLabelValues(evt.CommandName, failureCode(evt.Failure)).Inc()
+		},
+	}
+}
+
+// PoolMonitor returns a monitor recording the size, the use and the
+// checkouts of the connection pools.
+func PoolMonitor() *event.PoolMonitor {
+
+	return &event.PoolMonitor{
+		Event: func(evt *event.PoolEvent) {
+			switch evt.Type {
+			case event.ConnectionCreated:
+				MongoPoolConnections.WithLabelValues(evt.Address).Inc()
+			case event.ConnectionClosed:
+				MongoPoolConnections.WithLabelValues(evt.Address).Dec()
+			case event.GetSucceeded:
+				MongoPoolConnectionsInUse.WithLabelValues(evt.Address).Inc()
+				MongoPoolWaitDuration.WithLabelValues(evt.Address).Observe(evt.Duration.Seconds())
+			case event.ConnectionReturned:
+				MongoPoolConnectionsInUse.WithLabelValues(evt.Address).Dec()
+			case event.GetFailed:
+				MongoPoolWaitDuration.WithLabelValues(evt.Address).Observe(evt.Duration.Seconds())
+				MongoPoolCheckoutFailures.WithLabelValues(evt.Address, evt.Reason).Inc()
+			}
+		},
+	}
+}
+
+func (c *commandCollections) take(requestID int64) string {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	collection := c.collections[requestID]
+	delete(c.collections, requestID)
+
+	return collection
+}
+
+// collectionName returns the collection a command acts on, the value of its
+// first field for most commands, empty for the commands of no collection.
+func collectionName(command bson.Raw, name string) string {
+
+	key := name
+	if name == "getMore" {
+		key = "collection"
+	}
+
+	collection, _ := command.Lookup(key).StringValueOK()
+
+	return collection
+}
+
+// failureCode extracts the code name of a server error from a command
+// failure, formatted as "(NotWritablePrimary) message".
+func failureCode(failure string) string {
+
+	if !strings.HasPrefix(failure, "(") {
+		return unknownCode
+	}
+
+	code, _, ok := strings.Cut(failure[1:], ")")
+	if !ok || code == "" {
+		return unknownCode
+	}
+
+	return code
+}
@@ -0,0 +1,52 @@
+package observability
+
+import (
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+func TestFailureCode(t *testing.T) {
+
+	for failure, expected := range map[string]string{
+		"(NotWritablePrimary) not primary": "NotWritablePrimary",
+		"() empty":                         unknownCode,
+		"connection(localhost:27017[-3]) incomplete read of message header": unknownCode,
+		"": unknownCode,
+	} {
+		if code := failureCode(failure); code != expected {
+			t.Errorf("expected %q for %q, got %q", expected, failure, code)
+		}
+	}
+}
+
+func TestPoolMonitor(t *testing.T) {
+
+	const address = "pool-test:27017"
+
+	monitor := PoolMonitor()
+	for _, evt := range []event.PoolEvent{
+		{Type: event.ConnectionCreated, Address: address},
+		{Type: event.ConnectionCreated, Address: address},
+		{Type: event.GetSucceeded, Address: address, Duration: time.Millisecond},
+		{Type: event.GetSucceeded, Address: address, Duration: time.Millisecond},
+		{Type: event.ConnectionReturned, Address: address},
+		{Type: event.GetFailed, Address: address, Reason: event.ReasonTimedOut, Duration: time.Second},
+		{Type: event.ConnectionClosed, Address: address},
+	} {
+		evt := evt
+		monitor.Event(&evt)
+	}
+
+	if open := testutil.ToFloat64(MongoPoolConnections.WithLabelValues(address)); open != 1 {
+		t.Errorf("expected 1 open connection, got %v", open)
+	}
+	if inUse := testutil.ToFloat64(MongoPoolConnectionsInUse.WithLabelValues(address)); inUse != 1 {
+		t.Errorf("expected 1 connection in use, got %v", inUse)
+	}
+	if failures := testutil.ToFloat64(MongoPoolCheckoutFailures.WithLabelValues(address, event.ReasonTimedOut)); failures != 1 {
+		t.Errorf("expected 1 checkout failure, got %v", failures)
+	}
+}
@@ -0,0 +1,11 @@
+package observability
+
+import "github.com/prometheus/client_golang/prometheus"
+
+var ThrottledRequests = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "http_throttled_requests_total",
+		Help: "Number of requests rejected by the rate limiter.",
+	},
+	[]string{"route", "client"},
+)
@@ -0,0 +1,5 @@
+// Package peoplepb holds the protobuf definition of the PersonService and the
+// code generated from it.
+package peoplepb
+
+//go:generate protoc -I .. --go_out=.. --go_opt=paths=source_relative --go-grpc_out=.. --go-grpc_opt=paths=source_relative ../peoplepb/people.proto
@@ -0,0 +1,70 @@
+package profiler
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/google/pprof/profile"
+)
+
+var errNoProfiles = errors.New("no profile to merge")
+
+// Merge merges the CPU profiles at paths into out, such as the default.pgo
+// of the main package. out is replaced once the merged profile is complete.
+func Merge(out string, paths ...string) error {
+
+	if len(paths) == 0 {
+		return errNoProfiles
+	}
+
+	profiles := make([]*profile.Profile, 0, len(paths))
+
+	for _, path := range paths {
+		p, err := parse(path)
+		if err != nil {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+		profiles = append(profiles, p)
+	}
+
+	merged, err := profile.Merge(profiles)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(out), "."+filepath.Base(out)+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	// the temporary file is only readable by its owner
+	if err := f.Chmod(0o644); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := merged.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), out)
+}
+
+func parse(path string) (*profile.Profile, error) {
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return profile.Parse(f)
+}
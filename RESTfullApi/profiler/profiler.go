@@ -0,0 +1,247 @@
+// Package profiler captures CPU and heap profiles of the running service to a
+// directory, to refresh the default.pgo profile the builds are optimized
+// with.
+package profiler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// Profiler captures a CPU profile of duration every interval, along with
+	// a heap profile, and keeps the last ones of each kind.
+	Profiler struct {
+		logger   *slog.Logger
+		dir      string
+		interval time.Duration
+		duration time.Duration
+		keep     int
+		now      func() time.Time
+
+		cancel context.CancelFunc
+		done   chan struct{}
+		once   sync.Once
+	}
+
+	option func(profiler *Profiler)
+)
+
+const (
+	// CPU and Heap prefix the names of the profiles, followed by the UTC
+	// time they were captured at.
+	CPU  = "cpu"
+	Heap = "heap"
+
+	// Extension ends the names of the profiles.
+	Extension = ".pprof"
+
+	timeLayout = "20060102T150405Z"
+)
+
+// New returns a profiler writing to dir a 30 seconds CPU profile every 10
+// minutes and keeping the last 24 profiles of each kind.
+func New(logger *slog.Logger, dir string, opts ...option) *Profiler {
+
+	profiler := &Profiler{
+		logger:   logger,
+		dir:      dir,
+		interval: 10 * time.Minute,
+		duration: 30 * time.Second,
+		keep:     24,
+		now:      time.Now,
+	}
+
+	for i := range opts {
+		opts[i](profiler)
+	}
+
+	return profiler
+}
+
+// WithInterval sets the time between the start of two captures.
+func WithInterval(interval time.Duration) option {
+	return func(profiler *Profiler) {
+		profiler.interval = interval
+	}
+}
+
+// WithDuration sets how long the CPU is profiled, shorter than the interval.
+func WithDuration(duration time.Duration) option {
+	return func(profiler *Profiler) {
+		profiler.duration = duration
+	}
+}
+
+// WithKeep sets how many profiles of each kind are kept, the oldest ones are
+// removed.
+func WithKeep(keep int) option {
+	return func(profiler *Profiler) {
+		profiler.keep = keep
+	}
+}
+
+// Start creates the directory and captures the profiles in the background
+// until Stop is called.
+func (p *Profiler) Start() error {
+
+	if err := os.MkdirAll(p.dir, 0o755); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	p.done = make(chan struct{})
+
+	go func() {
+		defer close(p.done)
+		p.run(ctx)
+	}()
+
+	return nil
+}
+
+// Stop ends the running capture, keeping the profile captured so far, and
+// waits for it to be written or for ctx to be done.
+func (p *Profiler) Stop(ctx context.Context) error {
+
+	if p.cancel == nil {
+		return nil
+	}
+
+	p.once.Do(p.cancel)
+
+	select {
+	case <-p.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (p *Profiler) run(ctx context.Context) {
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if err := p.Capture(ctx); err != nil {
+			p.logger.Warn("Error while capturing the profiles", "dir", p.dir, "error", err)
+		}
+	}
+}
+
+// Capture profiles the CPU for the duration of the profiler, or until ctx is
+// done, then the heap, and removes the oldest profiles. The CPU profile is
+// skipped when another one is running, like one of /debug/pprof/profile.
+func (p *Profiler) Capture(ctx context.Context) error {
+
+	started := p.now().UTC()
+
+	cpuErr := p.write(CPU, started, func(f *os.File) error {
+
+		if err := pprof.StartCPUProfile(f); err != nil {
+			return err
+		}
+
+		timer := time.NewTimer(p.duration)
+		defer timer.Stop()
+
+		select {
+		case <-ctx.Done():
+		case <-timer.C:
+		}
+
+		pprof.StopCPUProfile()
+
+		return nil
+	})
+
+	heapErr := p.write(Heap, started, func(f *os.File) error {
+		return pprof.Lookup("heap").WriteTo(f, 0)
+	})
+
+	if cpuErr == nil && heapErr == nil {
+		p.logger.Debug("Profiles captured", "dir", p.dir, "at", started)
+	}
+
+	return errors.Join(cpuErr, heapErr, p.rotate(CPU), p.rotate(Heap))
+}
+
+// write writes a profile of kind with profile to a temporary file renamed
+// once complete, so that a merge never reads a partial profile.
+func (p *Profiler) write(kind string, at time.Time, profile func(f *os.File) error) error {
+
+	name := filepath.Join(p.dir, kind+"-"+at.Format(timeLayout)+Extension)
+
+	f, err := os.CreateTemp(p.dir, "."+kind+"-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+
+	if err := profile(f); err != nil {
+		f.Close()
+		return fmt.Errorf("%s profile: %w", kind, err)
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), name)
+}
+
+// rotate removes the oldest profiles of kind beyond the ones to keep.
+func (p *Profiler) rotate(kind string) error {
+
+	profiles, err := List(p.dir, kind)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for len(profiles) > p.keep {
+		errs = append(errs, os.Remove(profiles[0]))
+		profiles = profiles[1:]
+	}
+
+	return errors.Join(errs...)
+}
+
+// List returns the paths of the profiles of kind in dir, oldest first.
+func List(dir, kind string) ([]string, error) {
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.Type().IsRegular() && strings.HasPrefix(name, kind+"-") && strings.HasSuffix(name, Extension) {
+			profiles = append(profiles, filepath.Join(dir, name))
+		}
+	}
+
+	// the names end with the time of the capture
+	sort.Strings(profiles)
+
+	return profiles, nil
+}
@@ -0,0 +1,67 @@
+package profiler
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/pprof/profile"
+)
+
+func TestCaptureAndMerge(t *testing.T) {
+
+	dir := t.TempDir()
+	at := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	p := New(slog.New(slog.NewTextHandler(io.Discard, nil)), dir, WithDuration(20*time.Millisecond), WithKeep(2))
+	p.now = func() time.Time {
+		at = at.Add(time.Minute)
+		return at
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := p.Capture(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cpu, err := List(dir, CPU)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heap, err := List(dir, Heap)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(cpu) != 2 || len(heap) != 2 || filepath.Base(cpu[0]) != "cpu-20240101T000200Z.pprof" {
+		t.Fatalf("expected the last 2 profiles of each kind, got %v %v", cpu, heap)
+	}
+
+	out := filepath.Join(dir, "default.pgo")
+	if err := Merge(out, cpu...); err != nil {
+		t.Fatal(err)
+	}
+
+	f, err := os.Open(out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	merged, err := profile.Parse(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.SampleType) == 0 || merged.SampleType[0].Type != "samples" {
+		t.Errorf("expected a CPU profile, got %v", merged.SampleType)
+	}
+
+	if err := Merge(out); err != errNoProfiles {
+		t.Errorf("expected no profile to merge, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+// Package ratelimit throttles the clients of the API with token buckets.
+package ratelimit
+
+import (
+	"errors"
+	"math"
+	"sync"
+	"time"
+)
+
+type (
+	// Limit lets Requests requests through every Per, in bursts of up to
+	// Burst requests, Requests when Burst is zero.
+	Limit struct {
+		Requests int           `yaml:"requests"`
+		Per      time.Duration `yaml:"per"`
+		Burst    int           `yaml:"burst"`
+	}
+
+	bucket struct {
+		tokens float64
+		last   time.Time
+	}
+
+	// buckets holds a bucket per client of a route, the buckets left
+	// untouched long enough to be full again are dropped.
+	buckets struct {
+		mu         sync.Mutex
+		buckets    map[string]*bucket
+		lastSweep  time.Time
+		sweepEvery time.Duration
+	}
+
+	// decision is the outcome of taking a token from a bucket.
+	decision struct {
+		allowed    bool
+		remaining  int
+		reset      time.Duration
+		retryAfter time.Duration
+	}
+)
+
+var (
+	errNegativeLimit = errors.New("limits can't be negative")
+	errZeroPer       = errors.New("per must be positive when requests is set")
+)
+
+func (l Limit) capacity() float64 {
+
+	if l.Burst > 0 {
+		return float64(l.Burst)
+	}
+
+	return float64(l.Requests)
+}
+
+// rate is the number of tokens added per second.
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Per.Seconds()
+}
+
+func (l Limit) validate() error {
+
+	if l.Requests < 0 || l.Per < 0 || l.Burst < 0 {
+		return errNegativeLimit
+	}
+
+	if l.Requests > 0 && l.Per == 0 {
+		return errZeroPer
+	}
+
+	return nil
+}
+
+// Enabled reports whether the limit throttles anything.
+func (l Limit) Enabled() bool {
+	return l.Requests > 0 && l.Per > 0
+}
+
+func newBuckets() *buckets {
+	return &buckets{buckets: map[string]*bucket{}, sweepEvery: time.Minute}
+}
+
+func (b *buckets) take(key string, limit Limit, now time.Time) decision {
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if now.Sub(b.lastSweep) >= b.sweepEvery {
+		b.sweep(now, limit)
+		b.lastSweep = now
+	}
+
+	capacity, rate := limit.capacity(), limit.rate()
+
+	current, ok := b.buckets[key]
+	if !ok {
+		current = &bucket{tokens: capacity, last: now}
+		b.buckets[key] = current
+	}
+
+	current.tokens = math.Min(capacity, current.tokens+now.Sub(current.last).Seconds()*rate)
+	current.last = now
+
+	d := decision{allowed: current.tokens >= 1}
+	if d.allowed {
+		current.tokens--
+	} else {
+		d.retryAfter = seconds((1 - current.tokens) / rate)
+	}
+
+	d.remaining = int(current.tokens)
+	d.reset = seconds((capacity - current.tokens) / rate)
+
+	return d
+}
+
+// sweep drops the buckets full again at now, dropping a full bucket is the
+// same as keeping it.
+func (b *buckets) sweep(now time.Time, limit Limit) {
+
+	full := seconds(limit.capacity() / limit.rate())
+
+	for key, current := range b.buckets {
+		if now.Sub(current.last) >= full {
+			delete(b.buckets, key)
+		}
+	}
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,295 @@
+package ratelimit
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/observability"
+
+	"github.com/gorilla/mux"
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Config is the default limit, the optional limit of each address before
+	// authentication and the limits of named routes, as read from a file
+	// such as:
+	//
+	//	default:
+	//	  requests: 100
+	//	  per: 1m
+	//	address:
+	//	  requests: 1000
+	//	  per: 1m
+	//	routes:
+	//	  listPeople:
+	//	    requests: 10
+	//	    per: 1s
+	//	    burst: 20
+	Config struct {
+		Default Limit            `yaml:"default"`
+		Address Limit            `yaml:"address"`
+		Routes  map[string]Limit `yaml:"routes"`
+	}
+
+	// Limiter throttles each client of a route to the limit of the route,
+	// clients are told apart by API key, token subject or IP address.
+	Limiter struct {
+		logger      *slog.Logger
+		config      atomic.Pointer[Config]
+		proxyHeader string
+		now         func() time.Time
+
+		mu     sync.Mutex
+		routes map[string]*buckets
+	}
+
+	option func(limiter *Limiter)
+)
+
+const (
+	limitHeader      = "RateLimit-Limit"
+	remainingHeader  = "RateLimit-Remaining"
+	resetHeader      = "RateLimit-Reset"
+	policyHeader     = "RateLimit-Policy"
+	retryAfterHeader = "Retry-After"
+
+	setContentType        = "content-type"
+	jsonType              = "application/json"
+	errorWrittingResponse = "Error while writing the rate limit response"
+	tooManyRequests       = "Too many requests, retry in %d seconds"
+
+	keyClient     = "key"
+	subjectClient = "jwt"
+	ipClient      = "ip"
+
+	// addressBuckets prefixes the routes of the buckets of AddressMiddleware,
+	// kept apart from those of Middleware.
+	addressBuckets = "address:"
+)
+
+// LoadConfig reads the configuration file at path, an empty path returns
+// the configuration with defaultLimit only.
+func LoadConfig(path string, defaultLimit Limit) (Config, error) {
+
+	config := Config{Default: defaultLimit, Routes: map[string]Limit{}}
+
+	if path == "" {
+		return config, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return Config{}, err
+	}
+
+	if err := yaml.Unmarshal(content, &config); err != nil {
+		return Config{}, fmt.Errorf("parsing %s: %w", path, err)
+	}
+
+	if err := config.Default.validate(); err != nil {
+		return Config{}, fmt.Errorf("default: %w", err)
+	}
+
+	if err := config.Address.validate(); err != nil {
+		return Config{}, fmt.Errorf("address: %w", err)
+	}
+
+	for route, limit := range config.Routes {
+		if err := limit.validate(); err != nil {
+			return Config{}, fmt.Errorf("route %q: %w", route, err)
+		}
+	}
+
+	return config, nil
+}
+
+func NewLimiter(logger *slog.Logger, config Config, opts ...option) *Limiter {
+	limiter := &Limiter{
+		logger: logger,
+		now:    time.Now,
+		routes: map[string]*buckets{},
+	}
+	limiter.config.Store(&config)
+
+	for i := range opts {
+		opts[i](limiter)
+	}
+
+	return limiter
+}
+
+// SetConfig replaces the limits of the limiter, the buckets of the clients
+// are kept and refill at the new rates.
+func (l *Limiter) SetConfig(config Config) {
+	l.config.Store(&config)
+}
+
+// WithProxyHeader reads the client IP address from the first address of
+// header, such as X-Forwarded-For, when the API runs behind a proxy setting
+// it. Without a trusted proxy clients could pick their own address.
+func WithProxyHeader(header string) option {
+	return func(limiter *Limiter) {
+		limiter.proxyHeader = header
+	}
+}
+
+// Middleware throttles the requests of a route with the limit of its name,
+// or the default one. It must run after authentication to tell the clients
+// apart by credentials rather than by address.
+func (l *Limiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		route := routeName(request)
+
+		kind, client := l.client(request)
+
+		if l.throttle(response, request, route, l.limitOf(route), kind, client) {
+			return
+		}
+
+		next.ServeHTTP(response, request)
+	})
+}
+
+// AddressMiddleware throttles the requests of a route by IP address with the
+// address limit, it throttles nothing when the limit is not set: the clients
+// behind a proxy or a NAT share an address. It runs before authentication,
+// so that the requests with invalid credentials are throttled too.
+func (l *Limiter) AddressMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		route := routeName(request)
+
+		if l.throttle(response, request, addressBuckets+route, l.config.Load().Address, ipClient, ipClient+":"+l.clientIP(request)) {
+			return
+		}
+
+		next.ServeHTTP(response, request)
+	})
+}
+
+// limitOf returns the limit of route, or the default one.
+func (l *Limiter) limitOf(route string) Limit {
+
+	config := l.config.Load()
+
+	if limit, ok := config.Routes[route]; ok {
+		return limit
+	}
+
+	return config.Default
+}
+
+// throttle takes a token of the bucket of client among the buckets of key,
+// it answers 429 and reports true when there is none left.
+func (l *Limiter) throttle(response http.ResponseWriter, request *http.Request, key string, limit Limit, kind, client string) bool {
+
+	if !limit.Enabled() {
+		return false
+	}
+
+	d := l.bucketsOf(key).take(client, limit, l.now())
+
+	header := response.Header()
+	header.Set(limitHeader, strconv.Itoa(int(limit.capacity())))
+	header.Set(remainingHeader, strconv.Itoa(d.remaining))
+	header.Set(resetHeader, strconv.Itoa(ceilSeconds(d.reset)))
+	header.Set(policyHeader, fmt.Sprintf("%d;w=%d;burst=%d", limit.Requests, ceilSeconds(limit.Per), int(limit.capacity())))
+
+	if d.allowed {
+		return false
+	}
+
+	observability.ThrottledRequests.WithLabelValues(routeName(request), kind).Inc()
+
+	retryAfter := ceilSeconds(d.retryAfter)
+	header.Set(retryAfterHeader, strconv.Itoa(retryAfter))
+	header.Set(setContentType, jsonType)
+	response.WriteHeader(http.StatusTooManyRequests)
+
+	err := json.NewEncoder(response).Encode(struct {
+		Message string `json:"message"`
+	}{fmt.Sprintf(tooManyRequests, retryAfter)})
+	if err != nil {
+		l.logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+	}
+
+	return true
+}
+
+func (l *Limiter) bucketsOf(route string) *buckets {
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.routes[route]
+	if !ok {
+		b = newBuckets()
+		l.routes[route] = b
+	}
+
+	return b
+}
+
+// client returns the kind of the client of request and the key of its
+// bucket.
+func (l *Limiter) client(request *http.Request) (string, string) {
+
+	if principal, ok := auth.PrincipalFromContext(request.Context()); ok {
+		if principal.Key != nil {
+			return keyClient, keyClient + ":" + principal.Subject
+		}
+		if principal.Subject != "" {
+			return subjectClient, subjectClient + ":" + principal.Subject
+		}
+	}
+
+	return ipClient, ipClient + ":" + l.clientIP(request)
+}
+
+func (l *Limiter) clientIP(request *http.Request) string {
+
+	if l.proxyHeader != "" {
+		if forwarded := request.Header.Get(l.proxyHeader); forwarded != "" {
+			first, _, _ := strings.Cut(forwarded, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+
+	host, _, err := net.SplitHostPort(request.RemoteAddr)
+	if err != nil {
+		return request.RemoteAddr
+	}
+
+	return host
+}
+
+func routeName(request *http.Request) string {
+
+	if route := mux.CurrentRoute(request); route != nil {
+		if name := route.GetName(); name != "" {
+			return name
+		}
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+
+	return "unknown"
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,144 @@
+package ratelimit
+
+import (
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
+
+	"github.com/gorilla/mux"
+)
+
+func TestLimiter(t *testing.T) {
+
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	limiter := NewLimiter(slog.New(slog.NewTextHandler(io.Discard, nil)), Config{
+		Default: Limit{Requests: 100, Per: time.Minute},
+		Routes:  map[string]Limit{"listPeople": {Requests: 2, Per: 10 * time.Second}},
+	})
+	limiter.now = func() time.Time { return now }
+
+	router := mux.NewRouter()
+	router.Use(limiter.Middleware)
+	router.HandleFunc("/people", func(http.ResponseWriter, *http.Request) {}).Name("listPeople")
+
+	send := func(remoteAddr string, principal *auth.Principal) *httptest.ResponseRecorder {
+		request := httptest.NewRequest(http.MethodGet, "/people", nil)
+		request.RemoteAddr = remoteAddr
+		if principal != nil {
+			request = request.WithContext(auth.NewContext(request.Context(), principal))
+		}
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response
+	}
+
+	for i := 0; i < 2; i++ {
+		if response := send("10.0.0.1:1234", nil); response.Code != http.StatusOK {
+			t.Fatalf("request %d: expected 200, got %d", i+1, response.Code)
+		}
+	}
+
+	response := send("10.0.0.1:5678", nil)
+	if response.Code != http.StatusTooManyRequests {
+		t.Fatalf("expected 429, got %d", response.Code)
+	}
+	if got := response.Header().Get(retryAfterHeader); got != "5" {
+		t.Errorf("expected Retry-After 5, got %q", got)
+	}
+	if got := response.Header().Get(remainingHeader); got != "0" {
+		t.Errorf("expected RateLimit-Remaining 0, got %q", got)
+	}
+	if got := response.Header().Get(policyHeader); got != "2;w=10;burst=2" {
+		t.Errorf("unexpected RateLimit-Policy %q", got)
+	}
+
+	if response := send("10.0.0.2:1234", nil); response.Code != http.StatusOK {
+		t.Errorf("another address: expected 200, got %d", response.Code)
+	}
+
+	// a principal is limited by its credentials wherever it calls from
+	principal := &auth.Principal{Subject: "alice"}
+	send("10.0.0.3:1234", principal)
+	send("10.0.0.4:1234", principal)
+	if response := send("10.0.0.5:1234", principal); response.Code != http.StatusTooManyRequests {
+		t.Errorf("same subject: expected 429, got %d", response.Code)
+	}
+
+	now = now.Add(5 * time.Second)
+
+	response = send("10.0.0.1:1234", nil)
+	if response.Code != http.StatusOK {
+		t.Fatalf("after Retry-After: expected 200, got %d", response.Code)
+	}
+	if got := response.Header().Get(remainingHeader); got != "0" {
+		t.Errorf("expected RateLimit-Remaining 0, got %q", got)
+	}
+}
+
+func TestAddressMiddleware(t *testing.T) {
+
+	limiter := NewLimiter(slog.New(slog.NewTextHandler(io.Discard, nil)), Config{
+		Default: Limit{Requests: 2, Per: time.Minute},
+	})
+
+	router := mux.NewRouter()
+	router.Use(limiter.AddressMiddleware)
+	router.HandleFunc("/people", func(w http.ResponseWriter, r *http.Request) {
+		// authentication fails after the address limit
+		w.WriteHeader(http.StatusUnauthorized)
+	}).Name("listPeople")
+
+	send := func() int {
+		request := httptest.NewRequest(http.MethodGet, "/people", nil)
+		request.RemoteAddr = "10.0.0.1:1234"
+		request.Header.Set(auth.APIKeyHeader, "invalid")
+		response := httptest.NewRecorder()
+		router.ServeHTTP(response, request)
+		return response.Code
+	}
+
+	// without an address limit, the limit of the route is not applied by
+	// address
+	for i := 0; i < 3; i++ {
+		if code := send(); code != http.StatusUnauthorized {
+			t.Fatalf("without address limit, request %d: expected 401, got %d", i+1, code)
+		}
+	}
+
+	limiter.SetConfig(Config{Address: Limit{Requests: 2, Per: time.Minute}})
+
+	for i, expected := range []int{http.StatusUnauthorized, http.StatusUnauthorized, http.StatusTooManyRequests} {
+		if code := send(); code != expected {
+			t.Fatalf("request %d: expected %d, got %d", i+1, expected, code)
+		}
+	}
+}
+
+func TestLoadConfig(t *testing.T) {
+
+	for content, valid := range map[string]bool{
+		"default: {requests: 10, per: 1s}":             true,
+		"default: {requests: 10, per: 0s}":             false,
+		"default: {requests: -1, per: 1s}":             false,
+		"address: {requests: 10, per: 0s}":             false,
+		"routes: {listPeople: {requests: 1, per: 0s}}": false,
+		"routes: {listPeople: {burst: -1}}":            false,
+	} {
+		path := filepath.Join(t.TempDir(), "limits.yaml")
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := LoadConfig(path, Limit{}); (err == nil) != valid {
+			t.Errorf("%s: expected valid %v, got %v", content, valid, err)
+		}
+	}
+}
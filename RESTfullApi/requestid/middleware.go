@@ -0,0 +1,134 @@
+package requestid
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// errorWriter holds back the JSON error bodies, to add the request id to them.
+type errorWriter struct {
+	http.ResponseWriter
+	id          string
+	status      int
+	body        *bytes.Buffer
+	wroteHeader bool
+}
+
+const requestIDField = "request_id"
+
+// Middleware reads the id of each request from its X-Request-ID header,
+// falls back to the trace id of its traceparent header and generates one
+// when neither is valid. The id and the trace context, a new trace for the
+// requests that carry none, are stored in the request context, the tracer
+// provider of package tracing starts the span of the request in that new
+// trace so that the id is its trace id. The id is echoed in the X-Request-ID
+// header of the response and added to its JSON error bodies.
+func Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		trace, ok := ParseTraceparent(request.Header.Get(TraceparentHeader))
+		if !ok {
+			trace = TraceContext{TraceID: newID(16), ParentID: newID(8), Flags: "00"}
+		}
+
+		id := request.Header.Get(Header)
+		if !valid(id) {
+			id = trace.TraceID
+		}
+
+		ctx := NewTraceContext(NewContext(request.Context(), id), trace)
+
+		response.Header().Set(Header, id)
+
+		rw := &errorWriter{ResponseWriter: response, id: id}
+		next.ServeHTTP(rw, request.WithContext(ctx))
+		rw.flush()
+	})
+}
+
+func (w *errorWriter) WriteHeader(status int) {
+
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if status >= http.StatusBadRequest && isJSON(w.Header().Get("Content-Type")) {
+		w.status = status
+		w.body = &bytes.Buffer{}
+		return
+	}
+
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *errorWriter) Write(b []byte) (int, error) {
+
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+
+	if w.body != nil {
+		return w.body.Write(b)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *errorWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// flush writes the error body held back, with the request id.
+func (w *errorWriter) flush() {
+
+	if w.body == nil {
+		return
+	}
+
+	body := withRequestID(w.body.Bytes(), w.id)
+
+	w.Header().Del("Content-Length")
+	w.ResponseWriter.WriteHeader(w.status)
+	_, _ = w.ResponseWriter.Write(body)
+}
+
+// withRequestID adds the request_id field to body when it is a JSON object
+// without one, other bodies are returned as they are.
+func withRequestID(body []byte, id string) []byte {
+
+	// null unmarshals into a nil map
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(body, &fields); err != nil || fields == nil {
+		return body
+	}
+
+	if _, ok := fields[requestIDField]; ok {
+		return body
+	}
+
+	object := bytes.TrimRight(body, " \t\r\n")
+	value, _ := json.Marshal(id)
+
+	var out bytes.Buffer
+	out.Write(bytes.TrimRight(object[:len(object)-1], " \t\r\n"))
+	if len(fields) > 0 {
+		out.WriteByte(',')
+	}
+	out.WriteString(`"` + requestIDField + `":`)
+	out.Write(value)
+	out.WriteByte('}')
+	out.Write(body[len(object):])
+
+	return out.Bytes()
+}
+
+func isJSON(contentType string) bool {
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+
+	return err == nil && (mediaType == "application/json" || strings.HasSuffix(mediaType, "+json"))
+}
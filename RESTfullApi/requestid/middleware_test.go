@@ -0,0 +1,89 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestMiddleware(t *testing.T) {
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+
+	var seen string
+	handler := Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen = FromContext(r.Context())
+		if r.URL.Path == "/missing" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{ "message": "No Person was found" }` + "\n"))
+			return
+		}
+		if r.URL.Path == "/null" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`null`))
+			return
+		}
+		w.Write([]byte(`{}`))
+	}))
+
+	tests := []struct {
+		name        string
+		path        string
+		id          string
+		traceparent string
+		expected    string
+		body        string
+	}{
+		{"header", "/", "abc-123", "", "abc-123", `{}`},
+		{"invalid header", "/", "a b", "00-" + traceID + "-00f067aa0ba902b7-01", traceID, `{}`},
+		{"traceparent", "/", "", "00-" + traceID + "-00f067aa0ba902b7-01", traceID, `{}`},
+		{"error body", "/missing", "abc", "", "abc", `{ "message": "No Person was found","request_id":"abc"}` + "\n"},
+		{"null error body", "/null", "abc", "", "abc", `null`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, test.path, nil)
+			if test.id != "" {
+				request.Header.Set(Header, test.id)
+			}
+			if test.traceparent != "" {
+				request.Header.Set(TraceparentHeader, test.traceparent)
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if seen != test.expected || recorder.Header().Get(Header) != test.expected {
+				t.Fatalf("expected request id %q, got %q in the context and %q in the response", test.expected, seen, recorder.Header().Get(Header))
+			}
+			if recorder.Body.String() != test.body {
+				t.Fatalf("expected body %q, got %q", test.body, recorder.Body)
+			}
+		})
+	}
+
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/", nil))
+	if len(seen) != 32 || recorder.Header().Get(Header) != seen {
+		t.Fatalf("expected a generated request id, got %q", seen)
+	}
+}
+
+func TestParseTraceparent(t *testing.T) {
+
+	for value, valid := range map[string]bool{
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": true,
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01": false,
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-0000000000000000-01": false,
+		"01-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01": false,
+		"00-4BF92F3577B34DA6A3CE929D0E0E4736-00f067aa0ba902b7-01": false,
+		"": false,
+	} {
+		if _, ok := ParseTraceparent(value); ok != valid {
+			t.Errorf("expected %q valid %v", value, valid)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package requestid
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"regexp"
+	"strings"
+)
+
+type (
+	// TraceContext is the W3C trace context of a request, as carried by the
+	// traceparent header.
+	TraceContext struct {
+		TraceID  string
+		ParentID string
+		Flags    string
+	}
+
+	keyRequestID    struct{}
+	keyTraceContext struct{}
+)
+
+const (
+	// Header carries the id of a request, it is echoed in the responses.
+	Header = "X-Request-ID"
+	// TraceparentHeader carries the W3C trace context of a request.
+	TraceparentHeader = "traceparent"
+
+	maxLength = 128
+)
+
+var (
+	validID     = regexp.MustCompile(`^[A-Za-z0-9._:/+=-]+$`)
+	traceparent = regexp.MustCompile(`^00-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+)
+
+// NewContext returns a copy of ctx carrying the request id.
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, keyRequestID{}, id)
+}
+
+// FromContext returns the request id of ctx, empty when there is none.
+func FromContext(ctx context.Context) string {
+
+	id, _ := ctx.Value(keyRequestID{}).(string)
+
+	return id
+}
+
+// NewTraceContext returns a copy of ctx carrying the trace context.
+func NewTraceContext(ctx context.Context, trace TraceContext) context.Context {
+	return context.WithValue(ctx, keyTraceContext{}, trace)
+}
+
+// TraceFromContext returns the trace context of ctx.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+
+	trace, ok := ctx.Value(keyTraceContext{}).(TraceContext)
+
+	return trace, ok
+}
+
+// ParseTraceparent parses a version 00 traceparent header, the all zero trace
+// and parent ids are invalid.
+func ParseTraceparent(value string) (TraceContext, bool) {
+
+	match := traceparent.FindStringSubmatch(value)
+	if match == nil || match[1] == strings.Repeat("0", 32) || match[2] == strings.Repeat("0", 16) {
+		return TraceContext{}, false
+	}
+
+	return TraceContext{TraceID: match[1], ParentID: match[2], Flags: match[3]}, true
+}
+
+// String formats trace as a traceparent header.
+func (trace TraceContext) String() string {
+	return "00-" + trace.TraceID + "-" + trace.ParentID + "-" + trace.Flags
+}
+
+// valid reports whether id can be used as a request id, ids are echoed in
+// headers, logs and mongo comments so they are short and printable.
+func valid(id string) bool {
+	return len(id) <= maxLength && validID.MatchString(id)
+}
+
+// newID returns n random bytes, hex encoded.
+func newID(n int) string {
+
+	b := make([]byte, n)
+	// crypto/rand does not fail on the supported platforms
+	_, _ = rand.Read(b)
+
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,217 @@
+package store
+
+import (
+	"container/list"
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/observability"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type (
+	// CachedPersonStore keeps the people read by id in a bounded LRU cache in
+	// front of another store. Writes made through it drop the people they
+	// touch, writes made by other processes are seen once the cached people
+	// expire.
+	CachedPersonStore struct {
+		PersonStore
+
+		size         int
+		ttl          time.Duration
+		staleIfError time.Duration
+		partition    func(ctx context.Context) string
+		now          func() time.Time
+
+		mu      sync.Mutex
+		entries map[cacheKey]*list.Element
+		lru     *list.List
+		// generation is bumped by every invalidation so that a read racing
+		// with a write does not cache the person it replaced.
+		generation uint64
+	}
+
+	cacheKey struct {
+		partition string
+		id        primitive.ObjectID
+	}
+
+	cacheEntry struct {
+		key       cacheKey
+		person    data.Person
+		expiresAt time.Time
+	}
+
+	cacheOption func(cache *CachedPersonStore)
+)
+
+func NewCachedPersonStore(store PersonStore, size int, ttl time.Duration, opts ...cacheOption) *CachedPersonStore {
+	cache := &CachedPersonStore{
+		PersonStore: store,
+		size:        size,
+		ttl:         ttl,
+		partition:   func(context.Context) string { return "" },
+		now:         time.Now,
+		entries:     map[cacheKey]*list.Element{},
+		lru:         list.New(),
+	}
+
+	for i := range opts {
+		opts[i](cache)
+	}
+
+	return cache
+}
+
+// WithStaleIfError serves the people expired for less than stale when the
+// store fails to read them, e.g. when mongo is unreachable.
+func WithStaleIfError(stale time.Duration) cacheOption {
+	return func(cache *CachedPersonStore) {
+		cache.staleIfError = stale
+	}
+}
+
+// WithPartition separates the people cached for the contexts partition maps
+// to different values, such as the tenants of the requests.
+func WithPartition(partition func(ctx context.Context) string) cacheOption {
+	return func(cache *CachedPersonStore) {
+		cache.partition = partition
+	}
+}
+
+func (c *CachedPersonStore) GetByID(ctx context.Context, id primitive.ObjectID) (*data.Person, error) {
+
+	key := cacheKey{partition: c.partition(ctx), id: id}
+
+	c.mu.Lock()
+	entry, fresh := c.lookup(key)
+	generation := c.generation
+	c.mu.Unlock()
+
+	if fresh {
+		observability.CacheLookups.WithLabelValues(observability.CacheHit).Inc()
+		return &entry.person, nil
+	}
+
+	person, err := c.PersonStore.GetByID(ctx, id)
+	if err != nil {
+		if entry != nil && !errors.Is(err, data.ErrNotFound) {
+			observability.CacheLookups.WithLabelValues(observability.CacheStale).Inc()
+			return &entry.person, nil
+		}
+		observability.CacheLookups.WithLabelValues(observability.CacheMiss).Inc()
+		return nil, err
+	}
+
+	observability.CacheLookups.WithLabelValues(observability.CacheMiss).Inc()
+
+	c.mu.Lock()
+	if c.generation == generation {
+		c.add(key, *person)
+	}
+	c.mu.Unlock()
+
+	return person, nil
+}
+
+func (c *CachedPersonStore) Create(ctx context.Context, person data.Person) (primitive.ObjectID, error) {
+
+	id, err := c.PersonStore.Create(ctx, person)
+	if err == nil {
+		c.invalidate(ctx, id)
+	}
+
+	return id, err
+}
+
+func (c *CachedPersonStore) Update(ctx context.Context, id primitive.ObjectID, update data.PersonUpdate) (int64, error) {
+
+	defer c.invalidate(ctx, id)
+
+	return c.PersonStore.Update(ctx, id, update)
+}
+
+func (c *CachedPersonStore) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+
+	defer c.invalidate(ctx, id)
+
+	return c.PersonStore.Delete(ctx, id)
+}
+
+// Watch reports the changes of the wrapped store when it is a PersonWatcher.
+func (c *CachedPersonStore) Watch(ctx context.Context, onChange func(PersonChange) error) error {
+
+	watcher, ok := c.PersonStore.(PersonWatcher)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+
+	return watcher.Watch(ctx, onChange)
+}
+
+// lookup returns the entry of key and whether it is fresh, a copy of the
+// entry is only returned when it is fresh or may still be served stale.
+func (c *CachedPersonStore) lookup(key cacheKey) (*cacheEntry, bool) {
+
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+
+	entry := *element.Value.(*cacheEntry)
+	now := c.now()
+
+	if now.Before(entry.expiresAt) {
+		c.lru.MoveToFront(element)
+		return &entry, true
+	}
+
+	if now.Before(entry.expiresAt.Add(c.staleIfError)) {
+		return &entry, false
+	}
+
+	c.remove(element, observability.EvictedExpired)
+
+	return nil, false
+}
+
+func (c *CachedPersonStore) add(key cacheKey, person data.Person) {
+
+	entry := &cacheEntry{key: key, person: person, expiresAt: c.now().Add(c.ttl)}
+
+	if element, ok := c.entries[key]; ok {
+		element.Value = entry
+		c.lru.MoveToFront(element)
+		return
+	}
+
+	c.entries[key] = c.lru.PushFront(entry)
+
+	for c.lru.Len() > c.size {
+		c.remove(c.lru.Back(), observability.EvictedCapacity)
+	}
+}
+
+func (c *CachedPersonStore) invalidate(ctx context.Context, id primitive.ObjectID) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.generation++
+
+	if element, ok := c.entries[cacheKey{partition: c.partition(ctx), id: id}]; ok {
+		c.remove(element, observability.EvictedInvalidated)
+	}
+}
+
+func (c *CachedPersonStore) remove(element *list.Element, reason string) {
+
+	c.lru.Remove(element)
+	delete(c.entries, element.Value.(*cacheEntry).key)
+
+	observability.CacheEvictions.WithLabelValues(reason).Inc()
+}
@@ -0,0 +1,94 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// countingStore serves a person, and anonymous ones for the other ids, and
+// counts the reads reaching it.
+type countingStore struct {
+	PersonStore
+	person data.Person
+	reads  int
+	err    error
+}
+
+func (s *countingStore) GetByID(_ context.Context, id primitive.ObjectID) (*data.Person, error) {
+
+	s.reads++
+	if s.err != nil {
+		return nil, s.err
+	}
+	if id != s.person.ID {
+		return &data.Person{ID: id}, nil
+	}
+
+	person := s.person
+
+	return &person, nil
+}
+
+func (s *countingStore) Update(_ context.Context, _ primitive.ObjectID, update data.PersonUpdate) (int64, error) {
+
+	s.person.Firstname = update.Firstname
+
+	return 1, nil
+}
+
+func TestCachedPersonStore(t *testing.T) {
+
+	ctx := context.Background()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	inner := &countingStore{person: data.Person{ID: primitive.NewObjectID(), Firstname: "David"}}
+	cache := NewCachedPersonStore(inner, 1, time.Minute, WithStaleIfError(time.Hour))
+	cache.now = func() time.Time { return now }
+
+	get := func() string {
+		t.Helper()
+		person, err := cache.GetByID(ctx, inner.person.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return person.Firstname
+	}
+
+	get()
+	get()
+	if inner.reads != 1 {
+		t.Fatalf("expected a single read, got %d", inner.reads)
+	}
+
+	if _, err := cache.Update(ctx, inner.person.ID, data.PersonUpdate{Firstname: "Maria"}); err != nil {
+		t.Fatal(err)
+	}
+	if name := get(); name != "Maria" || inner.reads != 2 {
+		t.Fatalf("expected the update to be read, got %s after %d reads", name, inner.reads)
+	}
+
+	if _, err := cache.GetByID(ctx, primitive.NewObjectID()); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := cache.entries[cacheKey{id: inner.person.ID}]; ok {
+		t.Fatal("expected the size to be enforced")
+	}
+
+	get()
+	now = now.Add(2 * time.Minute)
+	inner.err = errors.New("mongo is down")
+	if name := get(); name != "Maria" {
+		t.Fatalf("expected the stale person, got %s", name)
+	}
+
+	now = now.Add(2 * time.Hour)
+	if _, err := cache.GetByID(ctx, inner.person.ID); err == nil {
+		t.Fatal("expected the error once the person is too stale")
+	}
+}
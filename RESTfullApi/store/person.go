@@ -0,0 +1,377 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/requestid"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// PersonStore is the storage shared by every API serving people.
+	PersonStore interface {
+		GetByID(ctx context.Context, id primitive.ObjectID) (*data.Person, error)
+		GetByIDs(ctx context.Context, ids []primitive.ObjectID) (data.People, error)
+		FindByName(ctx context.Context, name string) (data.People, error)
+		List(ctx context.Context, opts ListOptions) (data.People, error)
+		Create(ctx context.Context, person data.Person) (primitive.ObjectID, error)
+		Update(ctx context.Context, id primitive.ObjectID, update data.PersonUpdate) (int64, error)
+		Delete(ctx context.Context, id primitive.ObjectID) (int64, error)
+	}
+
+	// ListOptions narrows a List call. People are returned ordered by id,
+	// starting right after After when it is set.
+	ListOptions struct {
+		Firstname string
+		Lastname  string
+		After     primitive.ObjectID
+		Limit     int64
+	}
+
+	// PersonWatcher is implemented by stores able to report the changes made
+	// to people by any writer.
+	PersonWatcher interface {
+		// Watch calls onChange for every change until ctx is done or onChange
+		// returns an error.
+		Watch(ctx context.Context, onChange func(PersonChange) error) error
+	}
+
+	ChangeType int
+
+	// PersonChange describes a change to a person, Person is nil for deletions.
+	PersonChange struct {
+		Type   ChangeType
+		ID     primitive.ObjectID
+		Person *data.Person
+	}
+
+	MongoPersonStore struct {
+		collection *mongo.Collection
+		tenantID   string
+	}
+
+	// tenantPerson is how people are stored in a collection shared by tenants.
+	tenantPerson struct {
+		data.Person `bson:",inline"`
+		TenantID    string `bson:"tenantId"`
+	}
+
+	option func(store *MongoPersonStore)
+)
+
+const (
+	Created ChangeType = iota + 1
+	Updated
+	Deleted
+)
+
+const (
+	// TenantIDKey is the field separating the people of the tenants sharing a
+	// collection.
+	TenantIDKey = "tenantId"
+
+	idKey        = "_id"
+	firstnameKey = "firstname"
+	lastnameKey  = "lastname"
+	regexKey     = "$regex"
+	setCommand   = "$set"
+	inOperator   = "$in"
+	gtOperator   = "$gt"
+	regexOptions = "i"
+)
+
+func NewMongoPersonStore(collection *mongo.Collection, opts ...option) *MongoPersonStore {
+	store := &MongoPersonStore{collection: collection}
+
+	for i := range opts {
+		opts[i](store)
+	}
+
+	return store
+}
+
+// WithTenant restricts the store to the people of tenantID in a collection
+// shared by several tenants, people are created with the tenantId field and
+// every query filters on it.
+func WithTenant(tenantID string) option {
+	return func(store *MongoPersonStore) {
+		store.tenantID = tenantID
+	}
+}
+
+// GetByID returns data.ErrNotFound when no person has the given id.
+func (s *MongoPersonStore) GetByID(ctx context.Context, id primitive.ObjectID) (*data.Person, error) {
+
+	var person data.Person
+
+	opts := options.FindOne()
+	if comment, ok := commentOf(ctx); ok {
+		opts.SetComment(comment)
+	}
+	if maxTime, ok := maxTimeOf(ctx); ok {
+		opts.SetMaxTime(maxTime)
+	}
+
+	err := s.collection.FindOne(ctx, s.scope(bson.D{{Key: idKey, Value: id}}), opts).Decode(&person)
+
+	if err == mongo.ErrNoDocuments {
+		return nil, data.ErrNotFound
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &person, nil
+}
+
+// GetByIDs fetches several people with a single query, ids that do not exist
+// are left out of the result.
+func (s *MongoPersonStore) GetByIDs(ctx context.Context, ids []primitive.ObjectID) (data.People, error) {
+
+	return s.find(ctx, bson.D{{Key: idKey, Value: bson.D{{Key: inOperator, Value: ids}}}}, options.Find())
+}
+
+// FindByName returns the people whose firstname contains name as a whole word.
+func (s *MongoPersonStore) FindByName(ctx context.Context, name string) (data.People, error) {
+
+	const regexPattern = `(?:\A|\s)(%v)(?:\s|\z)`
+	pattern := fmt.Sprintf(regexPattern, name)
+
+	regexValue := primitive.Regex{Pattern: pattern, Options: regexOptions}
+
+	return s.find(ctx, bson.D{{Key: firstnameKey, Value: bson.D{{Key: regexKey, Value: regexValue}}}}, options.Find())
+}
+
+func (s *MongoPersonStore) List(ctx context.Context, opts ListOptions) (data.People, error) {
+
+	filter := bson.D{}
+
+	if opts.Firstname != "" {
+		filter = append(filter, bson.E{Key: firstnameKey, Value: exactMatch(opts.Firstname)})
+	}
+
+	if opts.Lastname != "" {
+		filter = append(filter, bson.E{Key: lastnameKey, Value: exactMatch(opts.Lastname)})
+	}
+
+	if !opts.After.IsZero() {
+		filter = append(filter, bson.E{Key: idKey, Value: bson.D{{Key: gtOperator, Value: opts.After}}})
+	}
+
+	findOptions := options.Find().SetSort(bson.D{{Key: idKey, Value: 1}})
+
+	if opts.Limit > 0 {
+		findOptions.SetLimit(opts.Limit)
+	}
+
+	return s.find(ctx, filter, findOptions)
+}
+
+// Create returns the id of the inserted person.
+func (s *MongoPersonStore) Create(ctx context.Context, person data.Person) (primitive.ObjectID, error) {
+
+	var document interface{} = person
+	if s.tenantID != "" {
+		document = tenantPerson{Person: person, TenantID: s.tenantID}
+	}
+
+	opts := options.InsertOne()
+	if comment, ok := commentOf(ctx); ok {
+		opts.SetComment(comment)
+	}
+
+	result, err := s.collection.InsertOne(ctx, document, opts)
+
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	id, ok := result.InsertedID.(primitive.ObjectID)
+	if !ok {
+		return primitive.NilObjectID, fmt.Errorf("unexpected inserted id type %T", result.InsertedID)
+	}
+
+	return id, nil
+}
+
+// Update sets the non empty fields of update and returns the number of
+// modified documents.
+func (s *MongoPersonStore) Update(ctx context.Context, id primitive.ObjectID, update data.PersonUpdate) (int64, error) {
+
+	opts := options.Update()
+	if comment, ok := commentOf(ctx); ok {
+		opts.SetComment(comment)
+	}
+
+	result, err := s.collection.UpdateOne(ctx, s.scope(bson.D{{Key: idKey, Value: id}}), bson.D{{Key: setCommand, Value: update}}, opts)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.ModifiedCount, nil
+}
+
+// Delete returns the number of deleted documents.
+func (s *MongoPersonStore) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+
+	opts := options.Delete()
+	if comment, ok := commentOf(ctx); ok {
+		opts.SetComment(comment)
+	}
+
+	result, err := s.collection.DeleteOne(ctx, s.scope(bson.D{{Key: idKey, Value: id}}), opts)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return result.DeletedCount, nil
+}
+
+// Watch follows a change stream on the collection, which requires MongoDB to
+// run as a replica set. Deletions carry no tenant, so a store restricted to a
+// tenant only reports creations and updates.
+func (s *MongoPersonStore) Watch(ctx context.Context, onChange func(PersonChange) error) error {
+
+	pipeline := mongo.Pipeline{}
+	if s.tenantID != "" {
+		pipeline = append(pipeline, bson.D{{Key: "$match", Value: bson.D{{Key: "fullDocument." + TenantIDKey, Value: s.tenantID}}}})
+	}
+
+	stream, err := s.collection.Watch(ctx, pipeline, options.ChangeStream().SetFullDocument(options.UpdateLookup))
+
+	if err != nil {
+		return err
+	}
+
+	defer stream.Close(context.Background())
+
+	for stream.Next(ctx) {
+
+		var event struct {
+			OperationType string `bson:"operationType"`
+			DocumentKey   struct {
+				ID primitive.ObjectID `bson:"_id"`
+			} `bson:"documentKey"`
+			FullDocument *data.Person `bson:"fullDocument"`
+		}
+
+		if err := stream.Decode(&event); err != nil {
+			return err
+		}
+
+		change := PersonChange{ID: event.DocumentKey.ID, Person: event.FullDocument}
+
+		switch event.OperationType {
+		case "insert":
+			change.Type = Created
+		case "update", "replace":
+			change.Type = Updated
+		case "delete":
+			change.Type = Deleted
+			change.Person = nil
+		default:
+			continue
+		}
+
+		if err := onChange(change); err != nil {
+			return err
+		}
+	}
+
+	return stream.Err()
+}
+
+func (s *MongoPersonStore) find(ctx context.Context, filter bson.D, opts *options.FindOptions) (data.People, error) {
+
+	if comment, ok := commentOf(ctx); ok {
+		opts.SetComment(comment)
+	}
+	if maxTime, ok := maxTimeOf(ctx); ok {
+		opts.SetMaxTime(maxTime)
+	}
+
+	cursor, err := s.collection.Find(ctx, s.scope(filter), opts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer cursor.Close(ctx)
+
+	var people data.People
+
+	for cursor.Next(ctx) {
+
+		var person data.Person
+		if err := cursor.Decode(&person); err != nil {
+			return nil, err
+		}
+		people = append(people, &person)
+	}
+
+	if err := cursor.Err(); err != nil {
+		return nil, err
+	}
+
+	return people, nil
+}
+
+// commentOf returns the request id of ctx, the commands of a request carry it
+// as their comment so that the slow queries logged by mongo can be matched
+// with the logs of the API.
+func commentOf(ctx context.Context) (string, bool) {
+
+	id := requestid.FromContext(ctx)
+
+	return id, id != ""
+}
+
+// maxTimeOf returns the time left before the deadline of ctx, sent as the
+// maxTimeMS of the queries so that mongo stops working on them once the
+// request is abandoned.
+func maxTimeOf(ctx context.Context) (time.Duration, bool) {
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		return 0, false
+	}
+
+	// maxTimeMS 0 means no limit, a query past its deadline fails on the
+	// context before being sent anyway
+	maxTime := time.Until(deadline).Round(time.Millisecond)
+	if maxTime < time.Millisecond {
+		maxTime = time.Millisecond
+	}
+
+	return maxTime, true
+}
+
+// scope restricts filter to the people of the tenant of the store.
+func (s *MongoPersonStore) scope(filter bson.D) bson.D {
+
+	if s.tenantID == "" {
+		return filter
+	}
+
+	return append(filter, bson.E{Key: TenantIDKey, Value: s.tenantID})
+}
+
+// exactMatch matches value case insensitively, it is escaped so user input
+// can't inject a regular expression.
+func exactMatch(value string) bson.D {
+
+	regexValue := primitive.Regex{Pattern: "^" + regexp.QuoteMeta(value) + "$", Options: regexOptions}
+
+	return bson.D{{Key: regexKey, Value: regexValue}}
+}
@@ -0,0 +1,95 @@
+package tenant
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const authorityMetadata = ":authority"
+
+// UnaryServerInterceptor resolves the tenant of the calls to the methods of
+// service like Middleware, from the metadata named after the header and from
+// the authority. Other services, such as health checks, are left alone. It
+// must run after the authentication interceptors.
+func (r *Resolver) UnaryServerInterceptor(service string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+
+		if !inService(info.FullMethod, service) {
+			return handler(ctx, req)
+		}
+
+		ctx, err := r.resolveCall(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// StreamServerInterceptor is the streaming counterpart of
+// UnaryServerInterceptor.
+func (r *Resolver) StreamServerInterceptor(service string) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+
+		if !inService(info.FullMethod, service) {
+			return handler(srv, ss)
+		}
+
+		ctx, err := r.resolveCall(ss.Context())
+		if err != nil {
+			return err
+		}
+
+		return handler(srv, &serverStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+func inService(fullMethod, service string) bool {
+	return strings.HasPrefix(fullMethod, "/"+service+"/")
+}
+
+type serverStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *serverStream) Context() context.Context {
+	return s.ctx
+}
+
+func (r *Resolver) resolveCall(ctx context.Context) (context.Context, error) {
+
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	t, err := r.Resolve(ctx, first(md.Get(r.header)), first(md.Get(authorityMetadata)))
+	if err != nil {
+		switch {
+		case errors.Is(err, errTenantRequired):
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		case errors.Is(err, ErrNotFound):
+			return nil, status.Error(codes.NotFound, err.Error())
+		case errors.Is(err, errTenantMismatch), errors.Is(err, errTenantUnbound):
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+		r.logger.ErrorContext(ctx, errorResolvingTenant, "error", err)
+		return nil, status.Error(codes.Unavailable, "the tenant could not be resolved")
+	}
+
+	return NewContext(ctx, t), nil
+}
+
+func first(values []string) string {
+
+	if len(values) == 0 {
+		return ""
+	}
+
+	return values[0]
+}
@@ -0,0 +1,159 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Handler serves the admin API provisioning and listing tenants.
+type Handler struct {
+	logger     *slog.Logger
+	registry   Registry
+	client     *mongo.Client
+	database   string
+	collection string
+	reserved   string
+	timeout    time.Duration
+}
+
+const (
+	errorDecodingTenant   = "Error while decoding the tenant"
+	errorProvisioning     = "Error while provisioning a tenant"
+	errorListingTenants   = "Error while listing the tenants"
+	errorWrittingTenants  = "Error while writing the tenants response"
+	tenantProvisioned     = "Tenant provisioned"
+	maxTenantRequestBytes = 1 << 16
+)
+
+// NewHandler returns the admin handler of the tenants, which get their
+// database or collection named after database and collection. The id of the
+// default tenant is reserved.
+func NewHandler(logger *slog.Logger, registry Registry, client *mongo.Client, database, collection string, defaultTenant *Tenant) *Handler {
+
+	handler := &Handler{
+		logger:     logger,
+		registry:   registry,
+		client:     client,
+		database:   database,
+		collection: collection,
+		timeout:    10 * time.Second,
+	}
+
+	if defaultTenant != nil {
+		handler.reserved = defaultTenant.ID
+	}
+
+	return handler
+}
+
+// ProvisionEndpoint creates the tenant of the body, {"id", "name", "isolation"}.
+func (h *Handler) ProvisionEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	var t Tenant
+	if err := json.NewDecoder(io.LimitReader(request.Body, maxTenantRequestBytes)).Decode(&t); err != nil {
+		h.logger.InfoContext(request.Context(), errorDecodingTenant, "error", err)
+		writeError(h.logger, response, request, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := t.Validate(h.database, h.collection); err != nil {
+		writeError(h.logger, response, request, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if t.ID == h.reserved {
+		writeError(h.logger, response, request, http.StatusConflict, ErrExists.Error())
+		return
+	}
+
+	t.CreatedAt = time.Now().UTC()
+
+	ctx, cancel := context.WithTimeout(request.Context(), h.timeout)
+	defer cancel()
+
+	if t.Isolation == IsolationField {
+		// the shared collection is queried by tenant first
+		_, err := h.client.Database(t.Database).Collection(t.Collection).Indexes().CreateOne(ctx, mongo.IndexModel{
+			Keys: bson.D{{Key: store.TenantIDKey, Value: 1}, {Key: "_id", Value: 1}},
+		})
+		if err != nil {
+			h.logger.ErrorContext(request.Context(), errorProvisioning, "tenant", t.ID, "error", err)
+			writeError(h.logger, response, request, http.StatusInternalServerError, err.Error())
+			return
+		}
+	}
+
+	if err := h.registry.Create(ctx, &t); err != nil {
+		if errors.Is(err, ErrExists) {
+			writeError(h.logger, response, request, http.StatusConflict, err.Error())
+			return
+		}
+		h.logger.ErrorContext(request.Context(), errorProvisioning, "tenant", t.ID, "error", err)
+		writeError(h.logger, response, request, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	h.logger.InfoContext(request.Context(), tenantProvisioned, "tenant", t.ID, "isolation", t.Isolation, "database", t.Database, "collection", t.Collection)
+
+	response.Header().Set(setContentType, jsonType)
+	response.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(response).Encode(&t); err != nil {
+		h.logger.ErrorContext(request.Context(), errorWrittingTenants, "error", err)
+	}
+}
+
+// ListEndpoint returns every provisioned tenant.
+func (h *Handler) ListEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	ctx, cancel := context.WithTimeout(request.Context(), h.timeout)
+	defer cancel()
+
+	tenants, err := h.registry.List(ctx)
+	if err != nil {
+		h.logger.ErrorContext(request.Context(), errorListingTenants, "error", err)
+		writeError(h.logger, response, request, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Header().Set(setContentType, jsonType)
+
+	if err := json.NewEncoder(response).Encode(tenants); err != nil {
+		h.logger.ErrorContext(request.Context(), errorWrittingTenants, "error", err)
+	}
+}
+
+// GetEndpoint returns the tenant with the id of the path.
+func (h *Handler) GetEndpoint(response http.ResponseWriter, request *http.Request) {
+
+	ctx, cancel := context.WithTimeout(request.Context(), h.timeout)
+	defer cancel()
+
+	t, err := h.registry.Get(ctx, mux.Vars(request)["id"])
+	if errors.Is(err, ErrNotFound) {
+		writeError(h.logger, response, request, http.StatusNotFound, err.Error())
+		return
+	}
+	if err != nil {
+		h.logger.ErrorContext(request.Context(), errorListingTenants, "error", err)
+		writeError(h.logger, response, request, http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	response.Header().Set(setContentType, jsonType)
+
+	if err := json.NewEncoder(response).Encode(t); err != nil {
+		h.logger.ErrorContext(request.Context(), errorWrittingTenants, "error", err)
+	}
+}
@@ -0,0 +1,245 @@
+package tenant
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/instrumentation"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/logging"
+)
+
+type (
+	// Source is where the tenant of a request is read from.
+	Source string
+
+	// Resolver finds the tenant of each request from its sources, in order,
+	// and falls back to the tenant the principal is bound to, then to the
+	// default tenant.
+	Resolver struct {
+		logger        *slog.Logger
+		registry      Registry
+		defaultTenant *Tenant
+		sources       []Source
+		header        string
+		baseDomain    string
+		timeout       time.Duration
+	}
+
+	option func(resolver *Resolver)
+)
+
+const (
+	// SourceHeader reads the tenant id from a header, X-Tenant-ID by default.
+	SourceHeader Source = "header"
+	// SourceSubdomain reads the tenant id from the first label of the host,
+	// as in acme.people.example.com for the base domain people.example.com.
+	SourceSubdomain Source = "subdomain"
+	// SourceClaim reads the tenant the principal is bound to by its API key
+	// or its token.
+	SourceClaim Source = "claim"
+
+	DefaultHeader = "X-Tenant-ID"
+
+	setContentType        = "content-type"
+	jsonType              = "application/json"
+	errorResolvingTenant  = "Error while resolving the tenant"
+	errorWrittingResponse = "Error while writing the error response"
+)
+
+var (
+	errTenantRequired = errors.New("a tenant is required")
+	errTenantMismatch = errors.New("the caller is bound to another tenant")
+	errTenantUnbound  = errors.New("the caller is not bound to a tenant, only the default one is allowed")
+)
+
+// ParseSources parses a comma separated list of sources.
+func ParseSources(value string) ([]Source, error) {
+
+	var sources []Source
+	for _, s := range strings.Split(value, ",") {
+		switch source := Source(strings.TrimSpace(s)); source {
+		case SourceHeader, SourceSubdomain, SourceClaim:
+			sources = append(sources, source)
+		case "":
+		default:
+			return nil, fmt.Errorf("unknown tenant source %q", source)
+		}
+	}
+
+	return sources, nil
+}
+
+// NewResolver returns a resolver falling back to defaultTenant, a nil
+// defaultTenant makes the tenant required.
+func NewResolver(logger *slog.Logger, registry Registry, defaultTenant *Tenant, opts ...option) *Resolver {
+	resolver := &Resolver{
+		logger:        logger,
+		registry:      registry,
+		defaultTenant: defaultTenant,
+		sources:       []Source{SourceHeader},
+		header:        DefaultHeader,
+		timeout:       5 * time.Second,
+	}
+
+	for i := range opts {
+		opts[i](resolver)
+	}
+
+	return resolver
+}
+
+func WithSources(sources []Source) option {
+	return func(resolver *Resolver) {
+		resolver.sources = sources
+	}
+}
+
+func WithHeader(header string) option {
+	return func(resolver *Resolver) {
+		resolver.header = header
+	}
+}
+
+// WithBaseDomain sets the domain the subdomains of the tenants are under.
+func WithBaseDomain(domain string) option {
+	return func(resolver *Resolver) {
+		resolver.baseDomain = strings.ToLower(strings.TrimPrefix(domain, "."))
+	}
+}
+
+func WithTimeout(timeout time.Duration) option {
+	return func(resolver *Resolver) {
+		resolver.timeout = timeout
+	}
+}
+
+// Middleware resolves the tenant of the request into its context. It must
+// run after authentication for the claim source and the tenant binding of
+// principals to apply.
+func (r *Resolver) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+		t, err := r.Resolve(request.Context(), request.Header.Get(r.header), request.Host)
+		if err != nil {
+			r.writeError(response, request, err)
+			return
+		}
+
+		instrumentation.SetTenantLabel(request, t.ID)
+		logging.AddAttrs(request.Context(), slog.String("tenant", t.ID))
+
+		next.ServeHTTP(response, request.WithContext(NewContext(request.Context(), t)))
+	})
+}
+
+// Resolve returns the tenant named by the header value or the host, as
+// configured by the sources, or by the principal of ctx. A principal bound to
+// no tenant only gets the default one, unless it is an admin.
+func (r *Resolver) Resolve(ctx context.Context, header, host string) (*Tenant, error) {
+
+	principal, _ := auth.PrincipalFromContext(ctx)
+
+	var id string
+	for _, source := range r.sources {
+		switch source {
+		case SourceHeader:
+			id = strings.TrimSpace(header)
+		case SourceSubdomain:
+			id = r.subdomain(host)
+		case SourceClaim:
+			if principal != nil {
+				id = principal.Tenant
+			}
+		}
+		if id != "" {
+			break
+		}
+	}
+
+	if principal != nil && principal.Tenant != "" {
+		if id == "" {
+			id = principal.Tenant
+		}
+		if id != principal.Tenant {
+			return nil, errTenantMismatch
+		}
+	}
+
+	if principal != nil && principal.Tenant == "" && !principal.HasScope(auth.ScopeAdmin) &&
+		id != "" && (r.defaultTenant == nil || id != r.defaultTenant.ID) {
+		return nil, errTenantUnbound
+	}
+
+	if id == "" {
+		if r.defaultTenant == nil {
+			return nil, errTenantRequired
+		}
+		return r.defaultTenant, nil
+	}
+
+	if r.defaultTenant != nil && id == r.defaultTenant.ID {
+		return r.defaultTenant, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	return r.registry.Get(ctx, id)
+}
+
+func (r *Resolver) subdomain(host string) string {
+
+	if r.baseDomain == "" {
+		return ""
+	}
+
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+
+	label, ok := strings.CutSuffix(strings.ToLower(host), "."+r.baseDomain)
+	if !ok || strings.Contains(label, ".") {
+		return ""
+	}
+
+	return label
+}
+
+func (r *Resolver) writeError(response http.ResponseWriter, request *http.Request, err error) {
+
+	status := http.StatusServiceUnavailable
+	switch {
+	case errors.Is(err, errTenantRequired):
+		status = http.StatusBadRequest
+	case errors.Is(err, ErrNotFound):
+		status = http.StatusNotFound
+	case errors.Is(err, errTenantMismatch), errors.Is(err, errTenantUnbound):
+		status = http.StatusForbidden
+	default:
+		r.logger.ErrorContext(request.Context(), errorResolvingTenant, "error", err)
+		err = errors.New("the tenant could not be resolved")
+	}
+
+	writeError(r.logger, response, request, status, err.Error())
+}
+
+func writeError(logger *slog.Logger, response http.ResponseWriter, request *http.Request, status int, message string) {
+
+	response.Header().Set(setContentType, jsonType)
+	response.WriteHeader(status)
+
+	err := json.NewEncoder(response).Encode(struct {
+		Message string `json:"message"`
+	}{message})
+	if err != nil {
+		logger.ErrorContext(request.Context(), errorWrittingResponse, "error", err)
+	}
+}
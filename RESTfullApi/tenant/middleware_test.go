@@ -0,0 +1,96 @@
+package tenant
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/auth"
+)
+
+type memoryRegistry map[string]*Tenant
+
+func (r memoryRegistry) Get(_ context.Context, id string) (*Tenant, error) {
+	if t, ok := r[id]; ok {
+		return t, nil
+	}
+	return nil, ErrNotFound
+}
+
+func (r memoryRegistry) List(context.Context) ([]*Tenant, error) { return nil, nil }
+
+func (r memoryRegistry) Create(_ context.Context, t *Tenant) error {
+	r[t.ID] = t
+	return nil
+}
+
+func TestResolver(t *testing.T) {
+
+	registry := memoryRegistry{}
+	for _, id := range []string{"acme", "globex"} {
+		tenant := &Tenant{ID: id, Isolation: IsolationField}
+		if err := tenant.Validate("people", "people"); err != nil {
+			t.Fatal(err)
+		}
+		registry.Create(context.Background(), tenant)
+	}
+
+	defaultTenant := &Tenant{ID: "default", Isolation: IsolationCollection}
+
+	resolver := NewResolver(slog.New(slog.NewTextHandler(io.Discard, nil)), registry, defaultTenant,
+		WithSources([]Source{SourceHeader, SourceSubdomain, SourceClaim}),
+		WithBaseDomain("people.example.com"),
+	)
+
+	handler := resolver.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		tenant, _ := FromContext(r.Context())
+		w.Write([]byte(tenant.ID))
+	}))
+
+	tests := []struct {
+		name      string
+		host      string
+		header    string
+		principal *auth.Principal
+		status    int
+		tenant    string
+	}{
+		{"default", "localhost:8080", "", nil, http.StatusOK, "default"},
+		{"header", "localhost:8080", "acme", nil, http.StatusOK, "acme"},
+		{"subdomain", "globex.people.example.com:443", "", nil, http.StatusOK, "globex"},
+		{"nested subdomain", "a.globex.people.example.com", "", nil, http.StatusOK, "default"},
+		{"unknown tenant", "localhost", "initech", nil, http.StatusNotFound, ""},
+		{"claim", "localhost", "", &auth.Principal{Tenant: "globex"}, http.StatusOK, "globex"},
+		{"bound to another tenant", "localhost", "acme", &auth.Principal{Tenant: "globex"}, http.StatusForbidden, ""},
+		{"unbound principal", "localhost", "acme", &auth.Principal{}, http.StatusForbidden, ""},
+		{"unbound principal by default", "localhost", "", &auth.Principal{}, http.StatusOK, "default"},
+		{"unbound principal naming the default", "localhost", "default", &auth.Principal{}, http.StatusOK, "default"},
+		{"unbound admin", "localhost", "acme", &auth.Principal{Scopes: []auth.Scope{auth.ScopeAdmin}}, http.StatusOK, "acme"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			request := httptest.NewRequest(http.MethodGet, "/v2/people", nil)
+			request.Host = test.host
+			if test.header != "" {
+				request.Header.Set(DefaultHeader, test.header)
+			}
+			if test.principal != nil {
+				request = request.WithContext(auth.NewContext(request.Context(), test.principal))
+			}
+
+			recorder := httptest.NewRecorder()
+			handler.ServeHTTP(recorder, request)
+
+			if recorder.Code != test.status {
+				t.Fatalf("expected %d, got %d: %s", test.status, recorder.Code, recorder.Body)
+			}
+			if test.status == http.StatusOK && recorder.Body.String() != test.tenant {
+				t.Fatalf("expected tenant %q, got %q", test.tenant, recorder.Body)
+			}
+		})
+	}
+}
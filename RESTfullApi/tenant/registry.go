@@ -0,0 +1,95 @@
+package tenant
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type (
+	// Registry holds the provisioned tenants.
+	Registry interface {
+		// Get returns ErrNotFound for unknown tenants.
+		Get(ctx context.Context, id string) (*Tenant, error)
+		List(ctx context.Context) ([]*Tenant, error)
+		// Create returns ErrExists when the id is taken.
+		Create(ctx context.Context, t *Tenant) error
+	}
+
+	// MongoRegistry keeps the tenants in a collection and caches the known
+	// ones for a TTL, so that resolving the tenant of a request rarely costs a
+	// query. Unknown ids are not cached, a tenant provisioned by another
+	// instance is found right away.
+	MongoRegistry struct {
+		collection *mongo.Collection
+		ttl        time.Duration
+
+		mu    sync.Mutex
+		cache map[string]cachedTenant
+	}
+
+	cachedTenant struct {
+		tenant    *Tenant
+		fetchedAt time.Time
+	}
+)
+
+func NewMongoRegistry(collection *mongo.Collection, ttl time.Duration) *MongoRegistry {
+	return &MongoRegistry{collection: collection, ttl: ttl, cache: map[string]cachedTenant{}}
+}
+
+func (r *MongoRegistry) Get(ctx context.Context, id string) (*Tenant, error) {
+
+	r.mu.Lock()
+	cached, ok := r.cache[id]
+	r.mu.Unlock()
+
+	if ok && time.Since(cached.fetchedAt) < r.ttl {
+		return cached.tenant, nil
+	}
+
+	var t Tenant
+
+	err := r.collection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&t)
+	if err == mongo.ErrNoDocuments {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	r.cache[id] = cachedTenant{tenant: &t, fetchedAt: time.Now()}
+	r.mu.Unlock()
+
+	return &t, nil
+}
+
+func (r *MongoRegistry) List(ctx context.Context) ([]*Tenant, error) {
+
+	cursor, err := r.collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, err
+	}
+
+	tenants := []*Tenant{}
+	if err := cursor.All(ctx, &tenants); err != nil {
+		return nil, err
+	}
+
+	return tenants, nil
+}
+
+func (r *MongoRegistry) Create(ctx context.Context, t *Tenant) error {
+
+	_, err := r.collection.InsertOne(ctx, t)
+	if mongo.IsDuplicateKeyError(err) {
+		return ErrExists
+	}
+
+	return err
+}
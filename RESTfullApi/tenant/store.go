@@ -0,0 +1,137 @@
+package tenant
+
+import (
+	"context"
+	"sync"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/data"
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/store"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Store is a store.PersonStore routing every call to the store of the tenant
+// of its context.
+type Store struct {
+	client *mongo.Client
+
+	mu     sync.Mutex
+	stores map[string]*store.MongoPersonStore
+}
+
+func NewStore(client *mongo.Client) *Store {
+	return &Store{client: client, stores: map[string]*store.MongoPersonStore{}}
+}
+
+// For returns the store of t.
+func (s *Store) For(t *Tenant) *store.MongoPersonStore {
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if personStore, ok := s.stores[t.ID]; ok {
+		return personStore
+	}
+
+	collection := s.client.Database(t.Database).Collection(t.Collection)
+
+	personStore := store.NewMongoPersonStore(collection)
+	if t.Isolation == IsolationField {
+		personStore = store.NewMongoPersonStore(collection, store.WithTenant(t.ID))
+	}
+
+	s.stores[t.ID] = personStore
+
+	return personStore
+}
+
+func (s *Store) tenantStore(ctx context.Context) (*store.MongoPersonStore, error) {
+
+	t, ok := FromContext(ctx)
+	if !ok {
+		return nil, ErrNoTenant
+	}
+
+	return s.For(t), nil
+}
+
+func (s *Store) GetByID(ctx context.Context, id primitive.ObjectID) (*data.Person, error) {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return personStore.GetByID(ctx, id)
+}
+
+func (s *Store) GetByIDs(ctx context.Context, ids []primitive.ObjectID) (data.People, error) {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return personStore.GetByIDs(ctx, ids)
+}
+
+func (s *Store) FindByName(ctx context.Context, name string) (data.People, error) {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return personStore.FindByName(ctx, name)
+}
+
+func (s *Store) List(ctx context.Context, opts store.ListOptions) (data.People, error) {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return personStore.List(ctx, opts)
+}
+
+func (s *Store) Create(ctx context.Context, person data.Person) (primitive.ObjectID, error) {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return primitive.NilObjectID, err
+	}
+
+	return personStore.Create(ctx, person)
+}
+
+func (s *Store) Update(ctx context.Context, id primitive.ObjectID, update data.PersonUpdate) (int64, error) {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return personStore.Update(ctx, id, update)
+}
+
+func (s *Store) Delete(ctx context.Context, id primitive.ObjectID) (int64, error) {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return personStore.Delete(ctx, id)
+}
+
+func (s *Store) Watch(ctx context.Context, onChange func(store.PersonChange) error) error {
+
+	personStore, err := s.tenantStore(ctx)
+	if err != nil {
+		return err
+	}
+
+	return personStore.Watch(ctx, onChange)
+}
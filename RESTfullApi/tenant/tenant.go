@@ -0,0 +1,86 @@
+// Package tenant maps the requests of each tenant to the people it owns.
+package tenant
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"time"
+)
+
+type (
+	// Isolation is how the people of a tenant are kept apart from the others.
+	Isolation string
+
+	Tenant struct {
+		ID         string    `json:"id" bson:"_id"`
+		Name       string    `json:"name" bson:"name"`
+		Isolation  Isolation `json:"isolation" bson:"isolation"`
+		Database   string    `json:"database" bson:"database"`
+		Collection string    `json:"collection" bson:"collection"`
+		CreatedAt  time.Time `json:"createdAt" bson:"createdAt"`
+	}
+
+	keyTenant struct{}
+)
+
+const (
+	// IsolationDatabase gives the tenant a database of its own.
+	IsolationDatabase Isolation = "database"
+	// IsolationCollection gives the tenant a collection of its own in the
+	// shared database.
+	IsolationCollection Isolation = "collection"
+	// IsolationField keeps the tenant in a collection shared by the tenants
+	// isolated by field, its people are marked with the tenantId field every
+	// query filters on.
+	IsolationField Isolation = "field"
+)
+
+var (
+	ErrNotFound = errors.New("unknown tenant")
+	ErrExists   = errors.New("tenant already exists")
+	ErrNoTenant = errors.New("no tenant in the context")
+
+	// ids end up in database and collection names
+	validID = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+)
+
+// NewContext returns a copy of ctx carrying t.
+func NewContext(ctx context.Context, t *Tenant) context.Context {
+	return context.WithValue(ctx, keyTenant{}, t)
+}
+
+// FromContext returns the tenant of ctx.
+func FromContext(ctx context.Context) (*Tenant, bool) {
+
+	t, ok := ctx.Value(keyTenant{}).(*Tenant)
+
+	return t, ok
+}
+
+// Validate checks the id of a tenant to provision and fills the database and
+// collection of its isolation from the shared ones.
+func (t *Tenant) Validate(database, collection string) error {
+
+	if !validID.MatchString(t.ID) {
+		return fmt.Errorf("tenant id %q must be 1 to 32 lower case letters, digits or dashes", t.ID)
+	}
+
+	switch t.Isolation {
+	case IsolationDatabase:
+		t.Database, t.Collection = database+"_"+t.ID, collection
+	case IsolationCollection:
+		t.Database, t.Collection = database, collection+"_"+t.ID
+	case IsolationField:
+		t.Database, t.Collection = database, collection+"_shared"
+	default:
+		return fmt.Errorf("unknown isolation %q, expected database, collection or field", t.Isolation)
+	}
+
+	if t.Name == "" {
+		t.Name = t.ID
+	}
+
+	return nil
+}
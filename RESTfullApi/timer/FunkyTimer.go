@@ -1,20 +1,20 @@
 package timer
 
 import (
-	"log"
+	"log/slog"
 	"time"
 )
 
 // var l = log.New(os.Stdout, "timer ", log.LstdFlags)
 
-func StartTimer(name string, logger *log.Logger) func() {
+func StartTimer(name string, logger *slog.Logger) func() {
 	t := time.Now()
-	logger.Println(name, "started")
+	logger.Debug("timer started", "name", name)
 
 	return func() {
 		// d := time.Now().Sub(t)
 		d := time.Since(t)
-		logger.Println(name, "took", d)
+		logger.Info("timer stopped", "name", name, "took", d)
 	}
 
 }
@@ -0,0 +1,46 @@
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/requestid"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// idGenerator starts the traces of the requests with the trace id of the
+// trace context requestid.Middleware stored, generated for the requests
+// without a traceparent, so that their request id is the id of their trace.
+// The other ids are random.
+type idGenerator struct{}
+
+func (idGenerator) NewIDs(ctx context.Context) (trace.TraceID, trace.SpanID) {
+
+	var traceID trace.TraceID
+
+	if tc, ok := requestid.TraceFromContext(ctx); ok {
+		traceID, _ = trace.TraceIDFromHex(tc.TraceID)
+	}
+
+	for !traceID.IsValid() {
+		// crypto/rand does not fail on the supported platforms
+		_, _ = rand.Read(traceID[:])
+	}
+
+	return traceID, newSpanID()
+}
+
+func (idGenerator) NewSpanID(context.Context, trace.TraceID) trace.SpanID {
+	return newSpanID()
+}
+
+func newSpanID() trace.SpanID {
+
+	var spanID trace.SpanID
+	for !spanID.IsValid() {
+		_, _ = rand.Read(spanID[:])
+	}
+
+	return spanID
+}
@@ -0,0 +1,63 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+type responseWriter struct {
+	http.ResponseWriter
+	statusCode int
+}
+
+func (rw *responseWriter) WriteHeader(code int) {
+	rw.statusCode = code
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+// Middleware starts a server span for every request, named after its method
+// and its route template, as a child of the trace context of its headers.
+// The route serving the metrics at metricsEndpoint is not traced.
+func Middleware(provider trace.TracerProvider, metricsEndpoint string) mux.MiddlewareFunc {
+
+	tracer := provider.Tracer(instrumentationName)
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(response http.ResponseWriter, request *http.Request) {
+
+			route, err := mux.CurrentRoute(request).GetPathTemplate()
+			if err != nil {
+				route = "unknown"
+			}
+
+			if route == metricsEndpoint {
+				next.ServeHTTP(response, request)
+				return
+			}
+
+			ctx := propagator.Extract(request.Context(), propagation.HeaderCarrier(request.Header))
+			ctx, span := tracer.Start(ctx, request.Method+" "+route,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					semconv.HTTPRequestMethodKey.String(request.Method),
+					semconv.HTTPRoute(route),
+					semconv.URLPath(request.URL.Path),
+				),
+			)
+			defer span.End()
+
+			rw := &responseWriter{response, http.StatusOK}
+			next.ServeHTTP(rw, request.WithContext(ctx))
+
+			span.SetAttributes(semconv.HTTPResponseStatusCode(rw.statusCode))
+			if rw.statusCode >= http.StatusInternalServerError {
+				span.SetStatus(codes.Error, http.StatusText(rw.statusCode))
+			}
+		})
+	}
+}
@@ -0,0 +1,100 @@
+package tracing
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/requestid"
+
+	"github.com/gorilla/mux"
+	"go.mongodb.org/mongo-driver/bson"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestMiddleware(t *testing.T) {
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	router := mux.NewRouter()
+	router.Use(Middleware(provider, "/metrics"))
+	router.HandleFunc("/person/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	router.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {})
+
+	request := httptest.NewRequest(http.MethodGet, "/person/1", nil)
+	request.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	router.ServeHTTP(httptest.NewRecorder(), request)
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	spans := recorder.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+
+	span := spans[0]
+	if span.Name() != "GET /person/{id}" {
+		t.Errorf("expected the span to be named after the route, got %q", span.Name())
+	}
+	if span.Parent().TraceID().String() != "4bf92f3577b34da6a3ce929d0e0e4736" || !span.Parent().IsRemote() {
+		t.Errorf("expected the span to continue the trace of the request, got parent %v", span.Parent())
+	}
+
+	var status int64
+	for _, attr := range span.Attributes() {
+		if attr.Key == semconv.HTTPResponseStatusCodeKey {
+			status = attr.Value.AsInt64()
+		}
+	}
+	if status != http.StatusInternalServerError || span.Status().Code.String() != "Error" {
+		t.Errorf("expected a failed span with status 500, got %d and %v", status, span.Status())
+	}
+}
+
+func TestMiddlewareRequestID(t *testing.T) {
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder), sdktrace.WithIDGenerator(idGenerator{}))
+
+	var traceID string
+	router := mux.NewRouter()
+	router.Use(Middleware(provider, "/metrics"))
+	router.HandleFunc("/people", func(w http.ResponseWriter, r *http.Request) {
+		traceID = trace.SpanContextFromContext(r.Context()).TraceID().String()
+	})
+
+	response := httptest.NewRecorder()
+	requestid.Middleware(router).ServeHTTP(response, httptest.NewRequest(http.MethodGet, "/people", nil))
+
+	if id := response.Header().Get(requestid.Header); id == "" || id != traceID {
+		t.Fatalf("expected the generated request id %q to be the trace id, got %q", id, traceID)
+	}
+}
+
+func TestCollectionName(t *testing.T) {
+
+	tests := []struct {
+		command  bson.D
+		name     string
+		expected string
+	}{
+		{bson.D{{Key: "find", Value: "people"}, {Key: "filter", Value: bson.D{}}}, "find", "people"},
+		{bson.D{{Key: "getMore", Value: int64(42)}, {Key: "collection", Value: "people"}}, "getMore", "people"},
+		{bson.D{{Key: "ping", Value: 1}}, "ping", ""},
+	}
+
+	for _, test := range tests {
+		raw, err := bson.Marshal(test.command)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if collection := collectionName(raw, test.name); collection != test.expected {
+			t.Errorf("expected %q for %s, got %q", test.expected, test.name, collection)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/event"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// commandSpans holds the spans of the commands in flight, by request id.
+type commandSpans struct {
+	tracer trace.Tracer
+	mu     sync.Mutex
+	spans  map[int64]trace.Span
+}
+
+// CommandMonitor returns a monitor starting a client span for every mongo
+// command, as a child of the span of the context of the operation. The
+// commands themselves are not recorded, they hold the people.
+func CommandMonitor(provider trace.TracerProvider) *event.CommandMonitor {
+
+	c := &commandSpans{tracer: provider.Tracer(instrumentationName), spans: map[int64]trace.Span{}}
+
+	return &event.CommandMonitor{
+		Started:   c.started,
+		Succeeded: c.succeeded,
+		Failed:    c.failed,
+	}
+}
+
+func (c *commandSpans) started(ctx context.Context, evt *event.CommandStartedEvent) {
+
+	attrs := []attribute.KeyValue{
+		semconv.DBSystemMongoDB,
+		semconv.DBOperationName(evt.CommandName),
+		semconv.DBNamespace(evt.DatabaseName),
+	}
+
+	name := evt.CommandName
+	if collection := collectionName(evt.Command, evt.CommandName); collection != "" {
+		name += " " + collection
+		attrs = append(attrs, semconv.DBCollectionName(collection))
+	}
+
+	if host, port, err := net.SplitHostPort(serverAddress(evt.ConnectionID)); err == nil {
+		attrs = append(attrs, semconv.ServerAddress(host))
+		if port, err := strconv.Atoi(port); err == nil {
+			attrs = append(attrs, semconv.ServerPort(port))
+		}
+	}
+
+	_, span := c.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+
+	c.mu.Lock()
+	c.spans[evt.RequestID] = span
+	c.mu.Unlock()
+}
+
+func (c *commandSpans) succeeded(_ context.Context, evt *event.CommandSucceededEvent) {
+
+	if span, ok := c.take(evt.RequestID); ok {
+		span.End()
+	}
+}
+
+func (c *commandSpans) failed(_ context.Context, evt *event.CommandFailedEvent) {
+
+	if span, ok := c.take(evt.RequestID); ok {
+		span.SetStatus(codes.Error, evt.Failure)
+		span.End()
+	}
+}
+
+func (c *commandSpans) take(requestID int64) (trace.Span, bool) {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	span, ok := c.spans[requestID]
+	delete(c.spans, requestID)
+
+	return span, ok
+}
+
+// collectionName returns the collection a command acts on, the value of its
+// first field for most commands.
+func collectionName(command bson.Raw, name string) string {
+
+	key := name
+	if name == "getMore" {
+		key = "collection"
+	}
+
+	collection, _ := command.Lookup(key).StringValueOK()
+
+	return collection
+}
+
+// serverAddress strips the connection number from a connection id, as in
+// localhost:27017[-4].
+func serverAddress(connectionID string) string {
+
+	address, _, _ := strings.Cut(connectionID, "[")
+
+	return address
+}
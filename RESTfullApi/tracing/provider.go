@@ -0,0 +1,139 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+type (
+	settings struct {
+		endpoint    string
+		file        string
+		sampleRatio float64
+		serviceName string
+	}
+
+	option func(settings *settings)
+)
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+
+	instrumentationName = "github/DavidHernandez21/RESTfullAPi-Golang/RESTfullApi/tracing"
+)
+
+// propagator reads the W3C trace context and baggage of the incoming requests.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// NewProvider returns a tracer provider sending the spans to exporter, along
+// with the function flushing them and releasing the exporter. The none
+// exporter returns a provider recording nothing.
+func NewProvider(ctx context.Context, exporter string, opts ...option) (trace.TracerProvider, func(context.Context) error, error) {
+
+	s := settings{sampleRatio: 1, serviceName: "people-api"}
+	for i := range opts {
+		opts[i](&s)
+	}
+
+	var (
+		spanExporter sdktrace.SpanExporter
+		closer       io.Closer
+		err          error
+	)
+
+	switch exporter {
+	case ExporterNone:
+		return noop.NewTracerProvider(), func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		var clientOpts []otlptracehttp.Option
+		if s.endpoint != "" {
+			clientOpts = append(clientOpts, otlptracehttp.WithEndpointURL(s.endpoint))
+		}
+		spanExporter, err = otlptracehttp.New(ctx, clientOpts...)
+	case ExporterStdout:
+		spanExporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		var f *os.File
+		f, err = os.OpenFile(s.file, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			break
+		}
+		closer = f
+		spanExporter, err = stdouttrace.New(stdouttrace.WithWriter(f))
+	default:
+		err = fmt.Errorf("unknown trace exporter %q", exporter)
+	}
+
+	if err != nil {
+		return nil, nil, fmt.Errorf("creating the %s trace exporter: %w", exporter, err)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewSchemaless(semconv.ServiceName(s.serviceName)))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(spanExporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(s.sampleRatio))),
+		sdktrace.WithIDGenerator(idGenerator{}),
+	)
+
+	shutdown := func(ctx context.Context) error {
+
+		err := provider.Shutdown(ctx)
+		if closer != nil {
+			if closeErr := closer.Close(); err == nil {
+				err = closeErr
+			}
+		}
+
+		return err
+	}
+
+	return provider, shutdown, nil
+}
+
+// WithEndpoint sets the URL of the OTLP/HTTP collector.
+func WithEndpoint(endpoint string) option {
+	return func(settings *settings) {
+		settings.endpoint = endpoint
+	}
+}
+
+// WithFile sets the file the file exporter appends the spans to, one JSON
+// document each.
+func WithFile(file string) option {
+	return func(settings *settings) {
+		settings.file = file
+	}
+}
+
+// WithSampleRatio sets the ratio of the traces started by the service that
+// are sampled, the traces started by the callers keep their decision.
+func WithSampleRatio(ratio float64) option {
+	return func(settings *settings) {
+		settings.sampleRatio = ratio
+	}
+}
+
+func WithServiceName(name string) option {
+	return func(settings *settings) {
+		settings.serviceName = name
+	}
+}